@@ -0,0 +1,196 @@
+package main
+
+import (
+	"cma-lab-go/io"
+	"cma-lab-go/matrix"
+	"context"
+	"errors"
+	"fmt"
+	goio "io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	AUTO_FORMAT          = "auto"
+	TEXT_FORMAT          = "text"
+	MATRIX_MARKET_FORMAT = "mtx"
+	CSV_FORMAT           = "csv"
+	TSV_FORMAT           = "tsv"
+	JSON_FORMAT          = "json"
+	NPY_FORMAT           = "npy"
+	NPZ_FORMAT           = "npz"
+	BINARY_FORMAT        = "bin"
+	GZIP_BINARY_FORMAT   = "bin.gz"
+	MMAP_FORMAT          = "mmap"
+	OCTAVE_FORMAT        = "octave"
+	MAT_FORMAT           = "mat"
+	MARKDOWN_FORMAT      = "markdown"
+	LATEX_FORMAT         = "latex"
+)
+
+var INPUT_FORMATS = []string{
+	AUTO_FORMAT, TEXT_FORMAT, MATRIX_MARKET_FORMAT, CSV_FORMAT, TSV_FORMAT, JSON_FORMAT, NPY_FORMAT, NPZ_FORMAT,
+	BINARY_FORMAT, GZIP_BINARY_FORMAT, MMAP_FORMAT, OCTAVE_FORMAT, MAT_FORMAT,
+}
+
+var formatExtensions = map[string]string{
+	".txt":   TEXT_FORMAT,
+	".mtx":   MATRIX_MARKET_FORMAT,
+	".csv":   CSV_FORMAT,
+	".tsv":   TSV_FORMAT,
+	".json":  JSON_FORMAT,
+	".jsonl": JSON_FORMAT,
+	".npy":   NPY_FORMAT,
+	".npz":   NPZ_FORMAT,
+	".bin":   BINARY_FORMAT,
+	".gz":    GZIP_BINARY_FORMAT,
+	".oct":   OCTAVE_FORMAT,
+	".mat":   MAT_FORMAT,
+	".md":    MARKDOWN_FORMAT,
+	".tex":   LATEX_FORMAT,
+}
+
+// formats holding a single matrix, the rest are read until the end of the file
+var singleMatrixFormats = map[string]bool{
+	MATRIX_MARKET_FORMAT: true,
+	CSV_FORMAT:           true,
+	TSV_FORMAT:           true,
+	NPY_FORMAT:           true,
+}
+
+// auto => by the file extension, text for the standard input/output and unknown extensions
+func detectFormat(filename, format string) string {
+	if format != AUTO_FORMAT {
+		return format
+	}
+	if filename == STDIO {
+		return TEXT_FORMAT
+	}
+	if format, ok := formatExtensions[strings.ToLower(filepath.Ext(filename))]; ok {
+		return format
+	}
+	return TEXT_FORMAT
+}
+
+func openInput(filename, format string) (io.MatrixReader, error) {
+	if filename == STDIO {
+		if isTerminal(os.Stdin) {
+			return nil, usageErrorf("no input file given")
+		}
+		switch format {
+		case TEXT_FORMAT:
+			return io.NewStreamMatrixReader(os.Stdin), nil
+		case BINARY_FORMAT:
+			return io.NewStreamBinaryMatrixReader(os.Stdin), nil
+		}
+		return nil, usageErrorf("%v input can't be read from the standard input", format)
+	}
+
+	switch format {
+	case TEXT_FORMAT:
+		return io.NewFileMatrixReader(filename)
+	case MATRIX_MARKET_FORMAT:
+		return io.NewMatrixMarketReader(filename)
+	case CSV_FORMAT:
+		return io.NewCSVMatrixReader(filename, io.CSVOptions{Delimiter: io.CSV_DELIMITER})
+	case TSV_FORMAT:
+		return io.NewTSVMatrixReader(filename, false)
+	case JSON_FORMAT:
+		return io.NewJSONMatrixReader(filename)
+	case NPY_FORMAT:
+		return io.NewNpyMatrixReader(filename)
+	case NPZ_FORMAT:
+		return io.NewNpzMatrixReader(filename)
+	case BINARY_FORMAT:
+		return io.NewBinaryMatrixReader(filename)
+	case GZIP_BINARY_FORMAT:
+		return io.NewGzipBinaryMatrixReader(filename)
+	case MMAP_FORMAT:
+		return io.NewMmapMatrixReader(filename)
+	case OCTAVE_FORMAT:
+		return io.NewOctaveMatrixReader(filename)
+	case MAT_FORMAT:
+		return io.NewMatMatrixReader(filename)
+	}
+	return nil, usageErrorf("unknown input format %q", format)
+}
+
+// matrices of any MatrixReader, like FileMatrixReader.Matrices does for the text format
+func readMatrices(ctx context.Context, reader io.MatrixReader, single bool) <-chan *io.StreamItem {
+	if fileReader, ok := reader.(*io.FileMatrixReader); ok {
+		return fileReader.Matrices(ctx)
+	}
+
+	items := make(chan *io.StreamItem)
+	go func() {
+		defer close(items)
+		for {
+			size, err := reader.ReadDimension()
+			if errors.Is(err, goio.EOF) {
+				return
+			}
+			item := &io.StreamItem{}
+			if err == nil {
+				item.Matrix, err = reader.ReadMatrix(size)
+			}
+			item.Err = err
+
+			select {
+			case items <- item:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil || single {
+				return
+			}
+		}
+	}()
+	return items
+}
+
+func readInput(filename, format string, first int, process func(index int, squareMatrix *matrix.SquareMatrix) error) (int, error) {
+	format = detectFormat(filename, format)
+	if err := checkChoice("input format", format, INPUT_FORMATS); err != nil {
+		return 0, err
+	}
+	reader, err := openInput(filename, format)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	// stop reading, if process fails
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	count := 0
+	for item := range readMatrices(ctx, reader, singleMatrixFormats[format]) {
+		if item.Err != nil {
+			return count, fmt.Errorf("%v: %w", inputName(filename), item.Err)
+		}
+		count++
+		if err := process(first+count, item.Matrix); err != nil {
+			return count, fmt.Errorf("%v: matrix #%v: %w", inputName(filename), first+count, err)
+		}
+	}
+
+	if count == 0 {
+		return 0, fmt.Errorf("%v: no matrices in the input", inputName(filename))
+	}
+	return count, nil
+}
+
+// calls process for every matrix of the inputs, the matrices are numbered from 1 through all the inputs
+func (opts *options) forEachMatrix(process func(index int, squareMatrix *matrix.SquareMatrix) error) error {
+	total := 0
+	for _, filename := range opts.inputs {
+		count, err := readInput(filename, opts.inputFormat, total, process)
+		if err != nil {
+			return err
+		}
+		total += count
+	}
+	return nil
+}
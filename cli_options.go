@@ -0,0 +1,159 @@
+package main
+
+import (
+	"cma-lab-go/cma_methods"
+	"cma-lab-go/io"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// "-" means the standard input or output
+const STDIO = "-"
+
+const (
+	DEFAULT_TOLERANCE          = 1e-10
+	DEFAULT_RESIDUAL_TOLERANCE = 1e-8
+	// the power method loops forever, if the dominant eigenvalues fit none of its cases
+	DEFAULT_POWER_MAX_ITERATIONS = 100000
+)
+
+// flags shared by the commands, every command registers only the ones it uses
+type options struct {
+	flags *flag.FlagSet
+
+	input       string
+	inputFormat string
+	// -input or the arguments
+	inputs []string
+
+	output       string
+	outputFormat string
+	precision    int
+
+	method        string
+	methods       []string
+	tolerance     float64
+	maxIterations int
+
+	workers int
+	seed    int64
+}
+
+func newOptions(name, arguments string) *options {
+	opts := &options{flags: flag.NewFlagSet(name, flag.ContinueOnError)}
+	opts.flags.Usage = func() {
+		fmt.Fprintf(opts.flags.Output(), "Usage: %v %v [flags] %v\n\nFlags:\n", PROGRAM_NAME, name, arguments)
+		opts.flags.PrintDefaults()
+	}
+	opts.flags.IntVar(&opts.workers, "workers", runtime.NumCPU(), "number of CPUs used by the parallel parts of the methods")
+	return opts
+}
+
+func (opts *options) inputFlags() {
+	opts.flags.StringVar(&opts.input, "input", STDIO, "input file, \"-\" is the standard input (files may be given as the arguments too)")
+	opts.flags.StringVar(&opts.inputFormat, "format", AUTO_FORMAT,
+		"input format: "+strings.Join(INPUT_FORMATS, ", ")+" (auto detects it by the file extension)")
+}
+
+func (opts *options) outputFlags(formats []string) {
+	opts.flags.StringVar(&opts.output, "o", STDIO, "output file, \"-\" is the standard output")
+	opts.flags.StringVar(&opts.outputFormat, "output", AUTO_FORMAT,
+		"output format: "+strings.Join(formats, ", ")+" (auto detects it by the file extension, text for the standard output)")
+	opts.flags.IntVar(&opts.precision, "precision", -1, "digits after the point, -1 is the shortest exact representation")
+}
+
+// the first method is the default one
+func (opts *options) methodFlag(methods []string) {
+	opts.methods = methods
+	opts.flags.StringVar(&opts.method, "method", methods[0], "method: "+strings.Join(methods, ", "))
+}
+
+func (opts *options) iterationFlags() {
+	opts.flags.Float64Var(&opts.tolerance, "tol", DEFAULT_TOLERANCE, "subdiagonal elements of the qr methods below it times the Frobenius norm are considered zero")
+	opts.flags.IntVar(&opts.maxIterations, "max-iter", 0,
+		fmt.Sprintf("limit of the qr and power method iterations, 0 means the default one: "+
+			"%v per eigenvalue (at least %v) for qr and %v for the power method",
+			cma_methods.QR_ITERATIONS_PER_EIGENVALUE, cma_methods.QR_MIN_ITERATIONS_LIMIT, DEFAULT_POWER_MAX_ITERATIONS))
+}
+
+func (opts *options) seedFlag() {
+	opts.flags.Int64Var(&opts.seed, "seed", 0, "seed of the random matrices, 0 means the current time")
+}
+
+func checkChoice(name, value string, choices []string) error {
+	for _, choice := range choices {
+		if value == choice {
+			return nil
+		}
+	}
+	return usageErrorf("unknown %v %q, expected one of: %v", name, value, strings.Join(choices, ", "))
+}
+
+// parses the flags and checks their values, the only positional argument is the input file
+func (opts *options) parse(args []string) error {
+	if err := opts.flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &usageError{err: err, reported: true}
+	}
+
+	switch {
+	case opts.flags.Lookup("input") == nil && opts.flags.NArg() > 0:
+		return usageErrorf("unexpected arguments: %v", strings.Join(opts.flags.Args(), " "))
+	case opts.flags.NArg() > 0 && opts.input != STDIO:
+		return usageErrorf("input is given both by -input and the arguments")
+	case opts.flags.NArg() > 0:
+		opts.inputs = opts.flags.Args()
+	default:
+		opts.inputs = []string{opts.input}
+	}
+
+	if opts.workers < 1 {
+		return usageErrorf("-workers must be positive, got %v", opts.workers)
+	}
+	runtime.GOMAXPROCS(opts.workers)
+
+	if opts.methods != nil {
+		if err := checkChoice("method", opts.method, opts.methods); err != nil {
+			return err
+		}
+	}
+	if opts.flags.Lookup("tol") != nil {
+		if !(opts.tolerance > 0) || math.IsInf(opts.tolerance, 0) {
+			return usageErrorf("-tol must be positive, got %v", opts.tolerance)
+		}
+		if opts.maxIterations < 0 {
+			return usageErrorf("-max-iter must not be negative, got %v", opts.maxIterations)
+		}
+	}
+	if opts.flags.Lookup("seed") != nil && opts.seed == 0 {
+		opts.seed = time.Now().UnixNano()
+	}
+	return nil
+}
+
+func (opts *options) numberFormat() io.NumberFormat {
+	format := io.DefaultNumberFormat
+	format.Precision = opts.precision
+	return format
+}
+
+// name of the input for the messages
+func inputName(filename string) string {
+	if filename == STDIO {
+		return "<stdin>"
+	}
+	return filename
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"cma-lab-go/io"
+	"cma-lab-go/matrix"
+	"fmt"
+	"os"
+)
+
+// formats of the command results, generate writes matrices in any of MATRIX_OUTPUT_FORMATS
+var (
+	RESULT_OUTPUT_FORMATS = []string{
+		AUTO_FORMAT, TEXT_FORMAT, JSON_FORMAT, CSV_FORMAT, TSV_FORMAT, MARKDOWN_FORMAT, LATEX_FORMAT,
+	}
+	MATRIX_OUTPUT_FORMATS = []string{
+		AUTO_FORMAT, TEXT_FORMAT, JSON_FORMAT, CSV_FORMAT, TSV_FORMAT, MARKDOWN_FORMAT, LATEX_FORMAT,
+		MATRIX_MARKET_FORMAT, NPY_FORMAT, NPZ_FORMAT, BINARY_FORMAT, GZIP_BINARY_FORMAT, OCTAVE_FORMAT, MAT_FORMAT,
+	}
+)
+
+// results of a command: matrices, eigen tables, any values for json and notes for text formats
+type output struct {
+	format string
+	file   *os.File
+	stream *bufio.Writer
+
+	writer io.MatrixWriter
+	json   *io.JSONMatrixWriter
+}
+
+// the formats writing to a file by its name only
+func (out *output) openFile(filename string) (io.MatrixWriter, error) {
+	if filename == STDIO {
+		return nil, usageErrorf("%v output can't be written to the standard output, use -o", out.format)
+	}
+
+	switch out.format {
+	case MATRIX_MARKET_FORMAT:
+		header := io.MatrixMarketHeader{
+			Format:   io.MATRIX_MARKET_ARRAY,
+			Field:    io.MATRIX_MARKET_REAL,
+			Symmetry: io.MATRIX_MARKET_GENERAL,
+		}
+		return io.NewMatrixMarketWriter(filename, header)
+	case NPY_FORMAT:
+		return io.NewNpyMatrixWriter(filename)
+	case NPZ_FORMAT:
+		return io.NewNpzMatrixWriter(filename, false)
+	case GZIP_BINARY_FORMAT:
+		return io.NewGzipBinaryMatrixWriter(filename, io.DefaultBinaryOptions)
+	case OCTAVE_FORMAT:
+		return io.NewOctaveMatrixWriter(filename)
+	case MAT_FORMAT:
+		return io.NewMatMatrixWriter(filename)
+	}
+	return nil, usageErrorf("unknown output format %q", out.format)
+}
+
+func (opts *options) openOutput(formats []string) (*output, error) {
+	out := &output{format: detectFormat(opts.output, opts.outputFormat)}
+	if err := checkChoice("output format", out.format, formats); err != nil {
+		return nil, err
+	}
+
+	var stream *os.File = os.Stdout
+	switch out.format {
+	case TEXT_FORMAT, JSON_FORMAT, CSV_FORMAT, TSV_FORMAT, MARKDOWN_FORMAT, LATEX_FORMAT, BINARY_FORMAT:
+		if opts.output != STDIO {
+			file, err := os.Create(opts.output)
+			if err != nil {
+				return nil, err
+			}
+			out.file, stream = file, file
+		}
+		out.stream = bufio.NewWriter(stream)
+	default:
+		writer, err := out.openFile(opts.output)
+		if err != nil {
+			return nil, err
+		}
+		out.writer = writer
+		return out, nil
+	}
+
+	switch out.format {
+	case TEXT_FORMAT:
+		out.writer = io.NewTextMatrixWriter(out.stream, opts.numberFormat())
+	case JSON_FORMAT:
+		out.json = io.NewJSONMatrixWriter(out.stream)
+		out.writer = out.json
+	case CSV_FORMAT:
+		out.writer = io.NewStreamCSVMatrixWriter(out.stream, io.CSVOptions{Delimiter: io.CSV_DELIMITER})
+	case TSV_FORMAT:
+		out.writer = io.NewStreamCSVMatrixWriter(out.stream, io.CSVOptions{Delimiter: io.TSV_DELIMITER})
+	case MARKDOWN_FORMAT:
+		out.writer = io.NewMarkdownMatrixWriter(out.stream, opts.numberFormat())
+	case LATEX_FORMAT:
+		out.writer = io.NewLatexMatrixWriter(out.stream, opts.numberFormat())
+	case BINARY_FORMAT:
+		out.writer = io.NewStreamBinaryMatrixWriter(out.stream, io.DefaultBinaryOptions)
+	}
+	return out, nil
+}
+
+// raw lines of the text format, other formats skip them
+func (out *output) note(format string, args ...interface{}) {
+	if out.format == TEXT_FORMAT {
+		fmt.Fprintf(out.stream, format, args...)
+	}
+}
+
+// title of a result: a line for text, a paragraph for markdown, other formats skip it
+func (out *output) header(format string, args ...interface{}) {
+	switch out.format {
+	case TEXT_FORMAT:
+		fmt.Fprintf(out.stream, format+"\n", args...)
+	case MARKDOWN_FORMAT:
+		fmt.Fprintf(out.stream, format+"\n\n", args...)
+	}
+}
+
+// json writes the value itself (e.g. a solver result), other formats write the eigen table
+func (out *output) eigenTable(eigenvalues []complex128, eigenvectors [][]float64, value interface{}) {
+	if out.json != nil {
+		out.json.WriteValue(value)
+		return
+	}
+	out.writer.(io.EigenTableWriter).WriteEigenTable(eigenvalues, eigenvectors)
+}
+
+// json writes the value itself, other formats write the row of numbers
+func (out *output) row(values []float64, value interface{}) {
+	if out.json != nil {
+		out.json.WriteValue(value)
+		return
+	}
+	out.writer.WriteRow(matrix.NewRow(values))
+}
+
+func (out *output) Close() error {
+	var err error
+	switch writer := out.writer.(type) {
+	case interface{ Close() error }:
+		err = writer.Close()
+	case interface{ Err() error }:
+		err = writer.Err()
+	}
+
+	keepError := func(e error) {
+		if err == nil {
+			err = e
+		}
+	}
+	if out.stream != nil {
+		keepError(out.stream.Flush())
+	}
+	if out.file != nil {
+		keepError(out.file.Close())
+	}
+	return err
+}
@@ -0,0 +1,197 @@
+package cma_methods
+
+import (
+	"cma-lab-go/matrix"
+	"fmt"
+	"math"
+)
+
+const (
+	BALANCING_RADIX  = 2
+	BALANCING_FACTOR = 0.95
+)
+
+// B = D^{-1} * P^T * A * P * D
+// eigenvalues of B and A are the same, eigenvectors are x = P * D * y
+type Balance struct {
+	// diagonal of D
+	Scale []float64
+	// P is the product of swaps [i, j] in the order they were applied
+	Swaps [][2]int
+	// rows and columns out of [Low, High] contain isolated eigenvalues
+	Low, High int
+}
+
+func swapRowsAndColumns(squareMatrix *matrix.SquareMatrix, i, j int) {
+	if i == j {
+		return
+	}
+
+	squareMatrix.Data[i], squareMatrix.Data[j] = squareMatrix.Data[j], squareMatrix.Data[i]
+	for k := range squareMatrix.Data {
+		squareMatrix.Data[k][i], squareMatrix.Data[k][j] = squareMatrix.Data[k][j], squareMatrix.Data[k][i]
+	}
+}
+
+// row (column) with all zero off-diagonal elements in [low, high] block
+func findIsolated(squareMatrix *matrix.SquareMatrix, low, high int, byRows bool) int {
+	for j := high; j >= low; j-- {
+		isolated := true
+		for i := low; i <= high && isolated; i++ {
+			if i == j {
+				continue
+			}
+			if byRows {
+				isolated = squareMatrix.Data[j][i] == 0
+			} else {
+				isolated = squareMatrix.Data[i][j] == 0
+			}
+		}
+		if isolated {
+			return j
+		}
+	}
+	return -1
+}
+
+// returns balanced copy of the matrix
+// scaling never stops on infinite elements, so non-finite matrices are rejected
+func BalanceMatrix(squareMatrixOriginal *matrix.SquareMatrix) (*matrix.SquareMatrix, *Balance, error) {
+	size := len(squareMatrixOriginal.Data)
+	for i, row := range squareMatrixOriginal.Data {
+		for j, v := range row {
+			if math.IsInf(v, 0) || math.IsNaN(v) {
+				return nil, nil, fmt.Errorf("element [%v][%v] is not finite", i, j)
+			}
+		}
+	}
+	squareMatrix := squareMatrixOriginal.Copy()
+	balance := &Balance{Scale: make([]float64, size), Low: 0, High: size - 1}
+	for i := range balance.Scale {
+		balance.Scale[i] = 1
+	}
+
+	// push rows isolating eigenvalues to the bottom
+	for balance.High > 0 {
+		j := findIsolated(squareMatrix, balance.Low, balance.High, true)
+		if j == -1 {
+			break
+		}
+		swapRowsAndColumns(squareMatrix, j, balance.High)
+		balance.Swaps = append(balance.Swaps, [2]int{j, balance.High})
+		balance.High--
+	}
+
+	// push columns isolating eigenvalues to the top
+	for balance.Low < balance.High {
+		j := findIsolated(squareMatrix, balance.Low, balance.High, false)
+		if j == -1 {
+			break
+		}
+		swapRowsAndColumns(squareMatrix, j, balance.Low)
+		balance.Swaps = append(balance.Swaps, [2]int{j, balance.Low})
+		balance.Low++
+	}
+
+	// scaling of the remaining block by powers of the radix
+	for converged := false; !converged; {
+		converged = true
+		for i := balance.Low; i <= balance.High; i++ {
+			var c, r float64 = 0, 0
+			for j := balance.Low; j <= balance.High; j++ {
+				if j != i {
+					c += math.Abs(squareMatrix.Data[j][i])
+					r += math.Abs(squareMatrix.Data[i][j])
+				}
+			}
+			if c == 0 || r == 0 {
+				continue
+			}
+
+			sum := c + r
+			var f float64 = 1
+			for g := r / BALANCING_RADIX; c < g; {
+				f *= BALANCING_RADIX
+				c *= BALANCING_RADIX * BALANCING_RADIX
+			}
+			for g := r * BALANCING_RADIX; c >= g; {
+				f /= BALANCING_RADIX
+				c /= BALANCING_RADIX * BALANCING_RADIX
+			}
+
+			if (c+r)/f >= BALANCING_FACTOR*sum {
+				continue
+			}
+
+			converged = false
+			balance.Scale[i] *= f
+			for j := 0; j < size; j++ {
+				squareMatrix.Data[i][j] /= f
+				squareMatrix.Data[j][i] *= f
+			}
+		}
+	}
+
+	return squareMatrix, balance, nil
+}
+
+// x = P * D * y
+func (balance *Balance) BackTransform(vector []float64) []float64 {
+	result := make([]float64, len(vector))
+	for i := range vector {
+		result[i] = vector[i] * balance.Scale[i]
+	}
+	for k := len(balance.Swaps) - 1; k >= 0; k-- {
+		i, j := balance.Swaps[k][0], balance.Swaps[k][1]
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+// P * D * M, for instance, transformation matrix of the balanced matrix
+func (balance *Balance) BackTransformMatrix(squareMatrix *matrix.SquareMatrix) *matrix.SquareMatrix {
+	result := squareMatrix.Copy()
+	for i := range result.Data {
+		for j := range result.Data[i] {
+			result.Data[i][j] *= balance.Scale[i]
+		}
+	}
+	for k := len(balance.Swaps) - 1; k >= 0; k-- {
+		i, j := balance.Swaps[k][0], balance.Swaps[k][1]
+		result.Data[i], result.Data[j] = result.Data[j], result.Data[i]
+	}
+	return result
+}
+
+// SolveQR of the balanced matrix, eigenvectors are transformed back
+// error for non-finite matrices and the ones, which QR iterations don't converge for (ErrQRNotConverged)
+func SolveQRBalanced(squareMatrix *matrix.SquareMatrix) ([]complex128, [][]float64, int, error) {
+	return SolveQRBalancedWithOptions(squareMatrix, DefaultQROptions)
+}
+
+func SolveQRBalancedWithOptions(squareMatrix *matrix.SquareMatrix, options QROptions) ([]complex128, [][]float64, int, error) {
+	balanced, balance, err := BalanceMatrix(squareMatrix)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	eigenvalues, eigenvectors, iterations, err := SolveQRWithOptions(balanced, options)
+	if err != nil {
+		return nil, nil, iterations, err
+	}
+	for i := range eigenvectors {
+		if eigenvectors[i] != nil {
+			eigenvectors[i] = balance.BackTransform(eigenvectors[i])
+		}
+	}
+	return eigenvalues, eigenvectors, iterations, nil
+}
+
+// FindPolynomial of the balanced matrix, transformation matrix is transformed back
+func FindPolynomialBalanced(squareMatrix *matrix.SquareMatrix) (Polynomial, *matrix.SquareMatrix, bool, error) {
+	balanced, balance, err := BalanceMatrix(squareMatrix)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	polynomial, transform, splitFlag := FindPolynomial(balanced)
+	return polynomial, balance.BackTransformMatrix(transform), splitFlag, nil
+}
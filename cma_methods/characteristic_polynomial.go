@@ -0,0 +1,205 @@
+package cma_methods
+
+import (
+	"cma-lab-go/matrix"
+	"cma-lab-go/utils"
+	"fmt"
+	"math"
+)
+
+// all methods return det(A - lambda * I), the same as FindPolynomial does
+type CharacteristicPolynomialMethod int
+
+const (
+	DANILEVSKII_METHOD CharacteristicPolynomialMethod = iota
+	LEVERRIER_FADDEEV_METHOD
+	KRYLOV_METHOD
+	HESSENBERG_METHOD
+)
+
+func (method CharacteristicPolynomialMethod) String() string {
+	switch method {
+	case DANILEVSKII_METHOD:
+		return "Danilevskii"
+	case LEVERRIER_FADDEEV_METHOD:
+		return "Leverrier-Faddeev"
+	case KRYLOV_METHOD:
+		return "Krylov"
+	case HESSENBERG_METHOD:
+		return "Hessenberg"
+	}
+	return fmt.Sprintf("CharacteristicPolynomialMethod(%d)", int(method))
+}
+
+// det(lambda * I - A) => det(A - lambda * I)
+func changeSign(polynomial Polynomial) Polynomial {
+	if (len(polynomial)-1)%2 == 1 {
+		return polynomial.Scale(-1)
+	}
+	return polynomial
+}
+
+func trace(squareMatrix *matrix.SquareMatrix) float64 {
+	var sum float64 = 0
+	for i := range squareMatrix.Data {
+		sum += squareMatrix.Data[i][i]
+	}
+	return sum
+}
+
+// M_1 = I, c_{n-k} = -tr(A * M_k) / k, M_{k+1} = A * M_k + c_{n-k} * I
+// returns polynomial, adjugate matrix and inverse matrix (error, if A is singular)
+func LeverrierFaddeev(squareMatrix *matrix.SquareMatrix) (Polynomial, *matrix.SquareMatrix, *matrix.SquareMatrix, error) {
+	size := len(squareMatrix.Data)
+	identity := utils.MakeIdentity(size)
+
+	coefficients := make(Polynomial, size+1)
+	coefficients[size] = 1
+
+	m := identity
+	var product *matrix.SquareMatrix
+	for k := 1; k <= size; k++ {
+		product, _ = matrix.MultiplyMatrices(squareMatrix, m)
+		coefficients[size-k] = -trace(product) / float64(k)
+		if k < size {
+			m = linearCombination([]float64{1, coefficients[size-k]},
+				[]*matrix.SquareMatrix{product, identity})
+		}
+	}
+
+	// adj(A) = (-1)^{n-1} * M_n, A^{-1} = -M_n / c_0
+	sign := 1.0
+	if (size-1)%2 == 1 {
+		sign = -1
+	}
+	adjugate := linearCombination([]float64{sign}, []*matrix.SquareMatrix{m})
+
+	polynomial := changeSign(coefficients)
+	if coefficients[0] == 0 {
+		return polynomial, adjugate, &matrix.SquareMatrix{}, fmt.Errorf("matrix is singular")
+	}
+	inverse := linearCombination([]float64{-1 / coefficients[0]}, []*matrix.SquareMatrix{m})
+	return polynomial, adjugate, inverse, nil
+}
+
+// y_0 = e_1, y_k = A * y_{k-1}, q_0 * y_0 + ... + q_{n-1} * y_{n-1} = -y_n
+// fails, if the Krylov subspace of e_1 is degenerate
+func KrylovPolynomial(squareMatrix *matrix.SquareMatrix) (Polynomial, error) {
+	size := len(squareMatrix.Data)
+
+	vectors := make([]*matrix.Column, 0, size+1)
+	start := make([]float64, size)
+	start[0] = 1
+	vectors = append(vectors, matrix.NewColumn(start))
+	for k := 1; k <= size; k++ {
+		next, _ := matrix.MultiplyMatrixOnColumn(squareMatrix, vectors[k-1])
+		vectors = append(vectors, next)
+	}
+
+	krylov := make([][]float64, size)
+	for i := 0; i < size; i++ {
+		krylov[i] = make([]float64, size)
+		for j := 0; j < size; j++ {
+			krylov[i][j] = vectors[j].Data[i]
+		}
+	}
+	krylovMatrix, _ := matrix.NewSquareMatrix(krylov)
+
+	lu, err := utils.NewLU(krylovMatrix)
+	if err != nil {
+		return Polynomial{}, fmt.Errorf("krylov vectors are linearly dependent")
+	}
+
+	right := make([]float64, size)
+	for i := 0; i < size; i++ {
+		right[i] = -vectors[size].Data[i]
+	}
+
+	coefficients := append(Polynomial(lu.Solve(right)), 1)
+	return changeSign(coefficients), nil
+}
+
+// p_0 = 1, p_k = (lambda - h_kk) * p_{k-1} - sum_{i<k} h_ik * h_{i+1,i} * ... * h_{k,k-1} * p_{i-1}
+// where p_k = det(lambda * I - H_k) for the leading submatrices of the Hessenberg matrix
+func HessenbergPolynomial(squareMatrix *matrix.SquareMatrix) Polynomial {
+	hessenberg, _ := reduceToHessenberg(squareMatrix)
+	h := hessenberg.Data
+	size := len(h)
+
+	polynomials := make([]Polynomial, 0, size+1)
+	polynomials = append(polynomials, Polynomial{1})
+	for k := 1; k <= size; k++ {
+		next := Polynomial{-h[k-1][k-1], 1}.Mul(polynomials[k-1])
+
+		product := 1.0
+		for i := k - 1; i >= 1; i-- {
+			product *= h[i][i-1]
+			next = next.Sub(polynomials[i-1].Scale(h[i-1][k-1] * product))
+		}
+		polynomials = append(polynomials, next)
+	}
+
+	return changeSign(polynomials[size])
+}
+
+// characteristic polynomial det(A - lambda * I) by the chosen method
+func FindCharacteristicPolynomial(squareMatrix *matrix.SquareMatrix, method CharacteristicPolynomialMethod) (Polynomial, error) {
+	switch method {
+	case DANILEVSKII_METHOD:
+		polynomial, _, _ := FindPolynomial(squareMatrix)
+		return polynomial, nil
+	case LEVERRIER_FADDEEV_METHOD:
+		polynomial, _, _, _ := LeverrierFaddeev(squareMatrix)
+		return polynomial, nil
+	case KRYLOV_METHOD:
+		return KrylovPolynomial(squareMatrix)
+	case HESSENBERG_METHOD:
+		return HessenbergPolynomial(squareMatrix), nil
+	}
+	return Polynomial{}, fmt.Errorf("unknown method %v", method)
+}
+
+type PolynomialDiscrepancy struct {
+	Method     CharacteristicPolynomialMethod
+	Polynomial Polynomial
+	Err        error
+	// max |a_i - b_i| / max(1, |b_i|), b is the reference polynomial
+	Discrepancy float64
+}
+
+// computes the polynomial by every method and compares coefficients with the reference one
+func ComparePolynomialMethods(squareMatrix *matrix.SquareMatrix, reference CharacteristicPolynomialMethod) ([]*PolynomialDiscrepancy, error) {
+	referencePolynomial, err := FindCharacteristicPolynomial(squareMatrix, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*PolynomialDiscrepancy
+	for _, method := range []CharacteristicPolynomialMethod{
+		DANILEVSKII_METHOD, LEVERRIER_FADDEEV_METHOD, KRYLOV_METHOD, HESSENBERG_METHOD,
+	} {
+		polynomial, err := FindCharacteristicPolynomial(squareMatrix, method)
+		discrepancy := &PolynomialDiscrepancy{Method: method, Polynomial: polynomial, Err: err}
+		if err != nil {
+			discrepancy.Discrepancy = math.Inf(1)
+			result = append(result, discrepancy)
+			continue
+		}
+
+		size := int(math.Max(float64(len(polynomial)), float64(len(referencePolynomial))))
+		for i := 0; i < size; i++ {
+			var a, b float64 = 0, 0
+			if i < len(polynomial) {
+				a = polynomial[i]
+			}
+			if i < len(referencePolynomial) {
+				b = referencePolynomial[i]
+			}
+			discrepancy.Discrepancy = math.Max(discrepancy.Discrepancy,
+				math.Abs(a-b)/math.Max(1, math.Abs(b)))
+		}
+		result = append(result, discrepancy)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package cma_methods
+
+import (
+	"cma-lab-go/matrix"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func scaledMatrix(data [][]float64, scale float64) *matrix.SquareMatrix {
+	scaled := make([][]float64, len(data))
+	for i := range data {
+		scaled[i] = make([]float64, len(data[i]))
+		for j := range data[i] {
+			scaled[i][j] = data[i][j] * scale
+		}
+	}
+	squareMatrix, _ := matrix.NewSquareMatrix(scaled)
+	return squareMatrix
+}
+
+func TestHessenbergPolynomial(t *testing.T) {
+	sampleA := [][]float64{{-24, 0, 25}, {25, 1, -25}, {-50, 0, 51}}
+	sampleB := [][]float64{{3, 4, 1}, {4, 5, 2}, {1, 2, 7}}
+	tests := []struct {
+		name  string
+		data  [][]float64
+		scale float64
+	}{
+		{"sampleA", sampleA, 1},
+		{"sampleB", sampleB, 1},
+		{"sampleB * 1e-11", sampleB, 1e-11},
+		{"sampleB * 1e-20", sampleB, 1e-20},
+		{"sampleA * 1e11", sampleA, 1e11},
+		{"non-symmetric 4x4 * 1e-12", [][]float64{{1, 2, 0, 3}, {4, 1, 2, 0}, {0, 5, 1, 1}, {2, 0, 3, 1}}, 1e-12},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			squareMatrix := scaledMatrix(test.data, test.scale)
+			exact, err := ExactCharacteristicPolynomial(squareMatrix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := exact.Float()
+			actual := HessenbergPolynomial(squareMatrix)
+			if len(actual) != len(expected) {
+				t.Fatalf("got %v, expected %v", actual, expected)
+			}
+
+			// coefficients have different scales (a_k ~ scale^(n - k)), each one is compared relatively
+			for k := range expected {
+				if math.Abs(actual[k]-expected[k]) > 1e-10*math.Abs(expected[k]) {
+					t.Errorf("coefficient %v is %v, expected %v", k, actual[k], expected[k])
+				}
+			}
+		})
+	}
+}
+
+func TestExactPolynomialString(t *testing.T) {
+	tests := []struct {
+		name string
+		data [][]float64
+	}{
+		{"sampleA", [][]float64{{-24, 0, 25}, {25, 1, -25}, {-50, 0, 51}}},
+		{"sampleB", [][]float64{{3, 4, 1}, {4, 5, 2}, {1, 2, 7}}},
+		{"zero", [][]float64{{0, 0}, {0, 0}}},
+		{"identity", [][]float64{{1, 0}, {0, 1}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			squareMatrix, _ := matrix.NewSquareMatrix(test.data)
+			exact, err := ExactCharacteristicPolynomial(squareMatrix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// integer coefficients are printed the same way
+			if exact.String() != exact.Float().String() {
+				t.Errorf("exact %q, float %q", exact.String(), exact.Float().String())
+			}
+		})
+	}
+
+	fractions := RationalPolynomial{big.NewRat(-3, 1), big.NewRat(1, 1), big.NewRat(1, 2), big.NewRat(-1, 1)}
+	if text := fractions.String(); text != "-x^3 + (1/2)x^2 + x - 3" {
+		t.Errorf("got %q", text)
+	}
+	if text := (RationalPolynomial{big.NewRat(1, 3)}).String(); text != "1/3" {
+		t.Errorf("got %q", text)
+	}
+}
@@ -0,0 +1,67 @@
+package cma_methods
+
+import (
+	"cma-lab-go/matrix"
+	"fmt"
+	"math"
+)
+
+// returns lower triangular L, such that A = L * L^T
+// A is expected to be symmetric positive definite
+func CholeskyDecomposition(squareMatrix *matrix.SquareMatrix) (*matrix.SquareMatrix, error) {
+	size := len(squareMatrix.Data)
+
+	data := make([][]float64, size)
+	for i := 0; i < size; i++ {
+		data[i] = make([]float64, size)
+	}
+
+	for i := 0; i < size; i++ {
+		for j := 0; j <= i; j++ {
+			sum := squareMatrix.Data[i][j]
+			for k := 0; k < j; k++ {
+				sum -= data[i][k] * data[j][k]
+			}
+
+			if i != j {
+				data[i][j] = sum / data[j][j]
+				continue
+			}
+
+			if sum <= 0 {
+				return &matrix.SquareMatrix{}, fmt.Errorf("matrix is not positive definite")
+			}
+			data[i][i] = math.Sqrt(sum)
+		}
+	}
+
+	return matrix.NewSquareMatrix(data)
+}
+
+// solves L * x = b, where L is lower triangular
+func solveLowerTriangular(lower *matrix.SquareMatrix, b []float64) []float64 {
+	size := len(lower.Data)
+	x := make([]float64, size)
+	for i := 0; i < size; i++ {
+		sum := b[i]
+		for k := 0; k < i; k++ {
+			sum -= lower.Data[i][k] * x[k]
+		}
+		x[i] = sum / lower.Data[i][i]
+	}
+	return x
+}
+
+// solves L^T * x = b, where L is lower triangular
+func solveLowerTransposed(lower *matrix.SquareMatrix, b []float64) []float64 {
+	size := len(lower.Data)
+	x := make([]float64, size)
+	for i := size - 1; i >= 0; i-- {
+		sum := b[i]
+		for k := i + 1; k < size; k++ {
+			sum -= lower.Data[k][i] * x[k]
+		}
+		x[i] = sum / lower.Data[i][i]
+	}
+	return x
+}
@@ -0,0 +1,67 @@
+package cma_methods
+
+import (
+	"cma-lab-go/matrix"
+)
+
+// Frobenius matrix (the same form as Danilevskii method produces):
+// the first row is -a_{n-1} / a_n, ..., -a_0 / a_n, ones are under the diagonal
+func CompanionMatrix(polynomial Polynomial) *matrix.SquareMatrix {
+	polynomial = polynomial.Trim()
+	deg := polynomial.Degree()
+	leading := polynomial[deg]
+
+	data := make([][]float64, deg)
+	for i := 0; i < deg; i++ {
+		data[i] = make([]float64, deg)
+		if i > 0 {
+			data[i][i-1] = 1
+		}
+	}
+	for j := 0; j < deg; j++ {
+		data[0][j] = -polynomial[deg-1-j] / leading
+	}
+
+	result, _ := matrix.NewSquareMatrix(data)
+	return result
+}
+
+// all complex roots (repeated according to multiplicity) as eigenvalues
+// of the balanced companion matrices of square-free factors
+// returns roots and total number of QR iterations
+func FindPolynomialRootsCompanion(polynomial Polynomial) ([]complex128, int, error) {
+	return FindPolynomialRootsCompanionWithOptions(polynomial, DefaultQROptions)
+}
+
+// error, if QR iterations didn't converge for one of the factors
+func FindPolynomialRootsCompanionWithOptions(polynomial Polynomial, options QROptions) ([]complex128, int, error) {
+	var roots []complex128
+	iterations := 0
+
+	for i, factor := range SquareFreeFactorization(polynomial) {
+		multiplicity := i + 1
+
+		var factorRoots []complex128
+		switch deg := factor.Degree(); {
+		case deg < 1:
+			continue
+		case deg == 1:
+			factorRoots = []complex128{complex(-factor[0]/factor[1], 0)}
+		default:
+			eigenvalues, _, factorIterations, err := SolveQRBalancedWithOptions(CompanionMatrix(factor), options)
+			iterations += factorIterations
+			if err != nil {
+				return nil, iterations, err
+			}
+			factorRoots = eigenvalues
+		}
+
+		for _, root := range factorRoots {
+			for k := 0; k < multiplicity; k++ {
+				roots = append(roots, root)
+			}
+		}
+	}
+
+	return cleanRoots(roots), iterations, nil
+}
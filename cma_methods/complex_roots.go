@@ -0,0 +1,120 @@
+package cma_methods
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+const SIMULTANEOUS_ITERATIONS_LIMIT = 1000
+
+// initial approximations are placed on the circle, the radius is the
+// geometric mean of roots moduli, the angle shift breaks the symmetry
+func initialRoots(polynomial Polynomial) []complex128 {
+	deg := len(polynomial) - 1
+	radius := math.Pow(math.Abs(polynomial[0]/polynomial[deg]), 1/float64(deg))
+	if radius == 0 || math.IsInf(radius, 0) || math.IsNaN(radius) {
+		_, radius = boundRoots(polynomial)
+	}
+
+	roots := make([]complex128, deg)
+	for k := range roots {
+		roots[k] = cmplx.Rect(radius, 2*math.Pi*float64(k)/float64(deg)+0.4)
+	}
+	return roots
+}
+
+// roots which are real up to the rounding errors are made real
+func cleanRoots(roots []complex128) []complex128 {
+	for k, z := range roots {
+		if math.Abs(imag(z)) <= ZERO_THRESHOLD*math.Max(1, cmplx.Abs(z)) {
+			roots[k] = complex(real(z), 0)
+		}
+	}
+	return roots
+}
+
+// Aberth-Ehrlich method, all roots are refined simultaneously:
+// z_k -= N_k / (1 - N_k * sum_{j != k} 1 / (z_k - z_j)), N_k = p(z_k) / p'(z_k)
+// returns all complex roots, number of iterations and whether they converged
+// in SIMULTANEOUS_ITERATIONS_LIMIT iterations (otherwise roots are rough approximations)
+func FindComplexPolynomialRoots(polynomial Polynomial) ([]complex128, int, bool) {
+	polynomial = polynomial.Trim()
+	if len(polynomial) < 2 {
+		return []complex128{}, 0, true
+	}
+
+	differential := polynomial.Derivative()
+	roots := initialRoots(polynomial)
+	iterations, converged := 0, false
+	for ; !converged && iterations < SIMULTANEOUS_ITERATIONS_LIMIT; iterations++ {
+		converged = true
+		for k := range roots {
+			p, dp := polynomial.ComplexValue(roots[k]), differential.ComplexValue(roots[k])
+			if p == 0 {
+				continue
+			}
+
+			var sum complex128 = 0
+			for j := range roots {
+				if j != k {
+					sum += 1 / (roots[k] - roots[j])
+				}
+			}
+
+			newton := p / dp
+			correction := newton / (1 - newton*sum)
+			if cmplx.IsNaN(correction) || cmplx.IsInf(correction) {
+				// p'(z) == 0, shake the approximation
+				correction = complex(NEWTON_ACCURACY, NEWTON_ACCURACY)
+			}
+
+			roots[k] -= correction
+			if cmplx.Abs(correction) > NEWTON_ACCURACY*math.Max(1, cmplx.Abs(roots[k])) {
+				converged = false
+			}
+		}
+	}
+
+	return cleanRoots(roots), iterations, converged
+}
+
+// Durand-Kerner (Weierstrass) method:
+// z_k -= p(z_k) / (a_n * prod_{j != k} (z_k - z_j))
+// returns all complex roots, number of iterations and whether they converged
+func FindComplexPolynomialRootsDurandKerner(polynomial Polynomial) ([]complex128, int, bool) {
+	polynomial = polynomial.Trim()
+	if len(polynomial) < 2 {
+		return []complex128{}, 0, true
+	}
+
+	leading := complex(polynomial[len(polynomial)-1], 0)
+	roots := initialRoots(polynomial)
+	iterations, converged := 0, false
+	for ; !converged && iterations < SIMULTANEOUS_ITERATIONS_LIMIT; iterations++ {
+		converged = true
+		for k := range roots {
+			p := polynomial.ComplexValue(roots[k])
+
+			product := leading
+			for j := range roots {
+				if j != k {
+					product *= roots[k] - roots[j]
+				}
+			}
+			if product == 0 {
+				// coincident approximations, shake one of them
+				roots[k] += complex(NEWTON_ACCURACY, NEWTON_ACCURACY)
+				converged = false
+				continue
+			}
+
+			correction := p / product
+			roots[k] -= correction
+			if cmplx.Abs(correction) > NEWTON_ACCURACY*math.Max(1, cmplx.Abs(roots[k])) {
+				converged = false
+			}
+		}
+	}
+
+	return cleanRoots(roots), iterations, converged
+}
@@ -0,0 +1,192 @@
+package cma_methods
+
+import (
+	"cma-lab-go/matrix"
+	"math"
+	"math/cmplx"
+)
+
+const INVERSE_ITERATIONS = 3
+
+// eigenvalues with condition number above this value are considered ill-conditioned
+const ILL_CONDITIONED_THRESHOLD = 1e6
+
+type EigenvalueCondition struct {
+	Value complex128
+	// right and left eigenvectors: A * x = lambda * x, y^T * A = lambda * y^T
+	Right []complex128
+	Left  []complex128
+	// ||x|| * ||y|| / |y^T * x|, perturbation of A by E moves lambda by about Condition * ||E||
+	Condition float64
+	// distance to the nearest other eigenvalue, it equals sep(lambda, T22) for normal matrices
+	// and bounds it from above otherwise
+	Separation float64
+	// estimate of eigenvector sensitivity: Condition / Separation
+	VectorCondition float64
+	IllConditioned  bool
+}
+
+// LU decomposition with partial pivoting of the complex matrix (in place)
+// zero pivots are replaced with the tiny value, so that the system
+// stays solvable (this is what inverse iteration needs), the flag is true then
+func complexLU(m [][]complex128) ([]int, bool) {
+	size := len(m)
+	pivots := make([]int, size)
+	tiny := complex(math.Max(frobeniusNorm(m), 1)*(math.Nextafter(1, 2)-1), 0)
+	singular := false
+
+	for k := 0; k < size; k++ {
+		maxInd := k
+		for i := k + 1; i < size; i++ {
+			if cmplx.Abs(m[i][k]) > cmplx.Abs(m[maxInd][k]) {
+				maxInd = i
+			}
+		}
+		pivots[k] = maxInd
+		m[k], m[maxInd] = m[maxInd], m[k]
+
+		if m[k][k] == 0 {
+			m[k][k] = tiny
+			singular = true
+		}
+
+		for i := k + 1; i < size; i++ {
+			m[i][k] /= m[k][k]
+			for j := k + 1; j < size; j++ {
+				m[i][j] -= m[i][k] * m[k][j]
+			}
+		}
+	}
+	return pivots, singular
+}
+
+func complexLUSolve(lu [][]complex128, pivots []int, b []complex128) []complex128 {
+	size := len(lu)
+	x := make([]complex128, size)
+	copy(x, b)
+
+	for k := 0; k < size; k++ {
+		x[k], x[pivots[k]] = x[pivots[k]], x[k]
+	}
+	for i := 0; i < size; i++ {
+		for k := 0; k < i; k++ {
+			x[i] -= lu[i][k] * x[k]
+		}
+	}
+	for i := size - 1; i >= 0; i-- {
+		for k := i + 1; k < size; k++ {
+			x[i] -= lu[i][k] * x[k]
+		}
+		x[i] /= lu[i][i]
+	}
+	return x
+}
+
+func complexVectorNorm(vector []complex128) float64 {
+	var sum float64 = 0
+	for _, v := range vector {
+		abs := cmplx.Abs(v)
+		sum += abs * abs
+	}
+	return math.Sqrt(sum)
+}
+
+// inverse iteration with the (A - lambda * I) or (A^T - lambda * I) matrix
+// returns normalized eigenvector
+func inverseIteration(squareMatrix *matrix.SquareMatrix, lambda complex128, transposed bool) []complex128 {
+	size := len(squareMatrix.Data)
+
+	shifted := make([][]complex128, size)
+	for i := 0; i < size; i++ {
+		shifted[i] = make([]complex128, size)
+		for j := 0; j < size; j++ {
+			if transposed {
+				shifted[i][j] = complex(squareMatrix.Data[j][i], 0)
+			} else {
+				shifted[i][j] = complex(squareMatrix.Data[i][j], 0)
+			}
+		}
+		shifted[i][i] -= lambda
+	}
+	pivots, _ := complexLU(shifted)
+
+	vector := make([]complex128, size)
+	for i := range vector {
+		vector[i] = 1
+	}
+
+	for iteration := 0; iteration < INVERSE_ITERATIONS; iteration++ {
+		vector = complexLUSolve(shifted, pivots, vector)
+		norm := complex(complexVectorNorm(vector), 0)
+		for i := range vector {
+			vector[i] /= norm
+		}
+	}
+	return vector
+}
+
+// computes condition numbers of eigenvalues (for instance, returned from SolveQR
+// or FindPolynomialRoots) using left and right eigenvectors
+func FindEigenvalueConditions(squareMatrix *matrix.SquareMatrix, eigenvalues []complex128) []*EigenvalueCondition {
+	conditions := make([]*EigenvalueCondition, 0, len(eigenvalues))
+
+	for i, lambda := range eigenvalues {
+		right := inverseIteration(squareMatrix, lambda, false)
+		left := inverseIteration(squareMatrix, lambda, true)
+
+		var product complex128 = 0
+		for k := range right {
+			product += left[k] * right[k]
+		}
+
+		condition := math.Inf(1)
+		if product != 0 {
+			condition = complexVectorNorm(left) * complexVectorNorm(right) / cmplx.Abs(product)
+		}
+
+		separation := math.Inf(1)
+		for j, other := range eigenvalues {
+			if j != i {
+				separation = math.Min(separation, cmplx.Abs(lambda-other))
+			}
+		}
+
+		conditions = append(conditions, &EigenvalueCondition{
+			Value:           lambda,
+			Right:           right,
+			Left:            left,
+			Condition:       condition,
+			Separation:      separation,
+			VectorCondition: condition / separation,
+			IllConditioned:  condition > ILL_CONDITIONED_THRESHOLD,
+		})
+	}
+
+	return conditions
+}
+
+// SolveQR with the condition numbers of the found eigenvalues,
+// ill-conditioned ones are flagged in result.Conditions
+func SolveQRWithConditions(squareMatrix *matrix.SquareMatrix, options QROptions) (*EigenResult, error) {
+	eigenvalues, eigenvectors, iterations, err := SolveQRWithOptions(squareMatrix, options)
+	if err != nil {
+		return nil, err
+	}
+	return &EigenResult{
+		Eigenvalues:  eigenvalues,
+		Eigenvectors: eigenvectors,
+		Iterations:   iterations,
+		Conditions:   FindEigenvalueConditions(squareMatrix, eigenvalues),
+	}, nil
+}
+
+// the conditions of the eigenvalues, which can't be trusted
+func IllConditioned(conditions []*EigenvalueCondition) []*EigenvalueCondition {
+	var result []*EigenvalueCondition
+	for _, condition := range conditions {
+		if condition.IllConditioned {
+			result = append(result, condition)
+		}
+	}
+	return result
+}
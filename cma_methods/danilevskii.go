@@ -7,29 +7,36 @@ import (
 	"sync"
 )
 
-func multiplyPolynomials(lhs, rhs []float64) []float64 {
-	n, m := len(lhs), len(rhs)
-	result := make([]float64, n+m-1)
+// rows and columns [Start, End) of the Frobenius matrix,
+// Polynomial is det(B - lambda * I) of the block B
+type FrobeniusBlock struct {
+	Start, End int
+	Polynomial Polynomial
+}
 
-	for k1, v1 := range lhs {
-		for k2, v2 := range rhs {
-			result[k1+k2] += v1 * v2
-		}
-	}
+// Matrix = Transform^{-1} * A * Transform is block upper triangular,
+// diagonal blocks are Frobenius matrices (the first row holds polynomial coefficients)
+// columns [0, Blocks[i].End) of Transform span an invariant subspace of A
+type FrobeniusForm struct {
+	Matrix    *matrix.SquareMatrix
+	Transform *matrix.SquareMatrix
+	// blocks are ordered by Start
+	Blocks []FrobeniusBlock
+}
 
-	last := n + m - 2
-	for ; last > 0 && math.Abs(result[last]) < 1e-9; {
-		last--
+// product of blocks polynomials without the negligible leading coefficients,
+// so the degree-based root finders get the right degree
+func (form *FrobeniusForm) Polynomial() Polynomial {
+	result := Polynomial{1}
+	for _, block := range form.Blocks {
+		result = result.Mul(block.Polynomial)
 	}
-
-	return result[:last+1]
+	return result.TrimLeading(LEADING_THRESHOLD)
 }
 
-// returns the polynomial, transformation matrix and boolean flag,
-// which says whether there was a block split in algorithm
-func FindPolynomial(squareMatrixOriginal *matrix.SquareMatrix) ([]float64, *matrix.SquareMatrix, bool) {
+// Danilevskii method, there is more than one block, if there was a block split
+func FindFrobeniusForm(squareMatrixOriginal *matrix.SquareMatrix) *FrobeniusForm {
 	size := len(squareMatrixOriginal.Data)
-	splitFlag := false
 	transform := utils.MakeIdentity(size)
 
 	// make a deep copy
@@ -40,7 +47,7 @@ func FindPolynomial(squareMatrixOriginal *matrix.SquareMatrix) ([]float64, *matr
 		copy(squareMatrix.Data[i], squareMatrixOriginal.Data[i])
 	}
 
-	var polynomials [][]float64
+	var blocks []FrobeniusBlock
 
 	var wg = sync.WaitGroup{}
 
@@ -73,7 +80,6 @@ func FindPolynomial(squareMatrixOriginal *matrix.SquareMatrix) ([]float64, *matr
 
 		if math.Abs(squareMatrix.Data[column+1][column]) < 1e-9 {
 			// split case
-			splitFlag = true
 
 			polynom := make([]float64, 0, prevSlice-column-1)
 			pol_len := prevSlice - column - 1
@@ -90,8 +96,8 @@ func FindPolynomial(squareMatrixOriginal *matrix.SquareMatrix) ([]float64, *matr
 				polynom = append(polynom, -1)
 			}
 
+			blocks = append([]FrobeniusBlock{{Start: column + 1, End: prevSlice, Polynomial: polynom}}, blocks...)
 			prevSlice = column + 1
-			polynomials = append(polynomials, polynom)
 			continue
 		}
 
@@ -148,14 +154,14 @@ func FindPolynomial(squareMatrixOriginal *matrix.SquareMatrix) ([]float64, *matr
 		polynom = append(polynom, -1)
 	}
 
-	polynomials = append(polynomials, polynom)
+	blocks = append([]FrobeniusBlock{{Start: 0, End: prevSlice, Polynomial: polynom}}, blocks...)
 
-	// multiply polynomials
-	result := make([]float64, 0)
-	result = append(result, 1)
-	for i, _ := range polynomials {
-		result = multiplyPolynomials(result, polynomials[i])
-	}
+	return &FrobeniusForm{Matrix: &squareMatrix, Transform: transform, Blocks: blocks}
+}
 
-	return result, transform, splitFlag
+// returns the polynomial, transformation matrix and boolean flag,
+// which says whether there was a block split in algorithm
+func FindPolynomial(squareMatrixOriginal *matrix.SquareMatrix) (Polynomial, *matrix.SquareMatrix, bool) {
+	form := FindFrobeniusForm(squareMatrixOriginal)
+	return form.Polynomial(), form.Transform, len(form.Blocks) > 1
 }
@@ -0,0 +1,136 @@
+package cma_methods
+
+import (
+	"cma-lab-go/matrix"
+	"cma-lab-go/utils"
+	"math"
+	"math/cmplx"
+)
+
+// relative residual ||A * x - lambda * x|| / (||A|| * ||x||) above this value
+// means that the Frobenius vector construction is ill-conditioned
+const EIGENVECTOR_RESIDUAL_THRESHOLD = 1e-8
+
+type DanilevskiiEigenvector struct {
+	Eigenvector
+	// index of the Frobenius block, which polynomial has the eigenvalue as a root
+	Block int
+	// true if the Frobenius vector was ill-conditioned and inverse iteration was used
+	InverseIteration bool
+}
+
+// eigenvector y of the block upper triangular Frobenius matrix F:
+// y is zero in blocks after the block, the block part is (lambda^{L-1}, ..., lambda, 1)
+// and the previous blocks are solved from (F_jj - lambda * I) * y_j = -sum_{l>j} F_jl * y_l
+// (complex for complex eigenvalues)
+func (form *FrobeniusForm) blockEigenvector(block int, eigenvalue complex128) ([]complex128, bool) {
+	size := len(form.Matrix.Data)
+	f := form.Matrix.Data
+	y := make([]complex128, size)
+
+	start, end := form.Blocks[block].Start, form.Blocks[block].End
+	var power complex128 = 1
+	for i := end - 1; i >= start; i-- {
+		y[i] = power
+		power *= eigenvalue
+	}
+
+	for j := block - 1; j >= 0; j-- {
+		start, end := form.Blocks[j].Start, form.Blocks[j].End
+
+		shifted := make([][]complex128, end-start)
+		right := make([]complex128, end-start)
+		for i := start; i < end; i++ {
+			shifted[i-start] = make([]complex128, end-start)
+			for l := start; l < end; l++ {
+				shifted[i-start][l-start] = complex(f[i][l], 0)
+			}
+			shifted[i-start][i-start] -= eigenvalue
+
+			for l := end; l < size; l++ {
+				right[i-start] -= complex(f[i][l], 0) * y[l]
+			}
+		}
+
+		pivots, singular := complexLU(shifted)
+		if singular {
+			// eigenvalue is shared with the previous block
+			return nil, false
+		}
+		copy(y[start:end], complexLUSolve(shifted, pivots, right))
+	}
+
+	x := make([]complex128, size)
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			x[i] += complex(form.Transform.Data[i][j], 0) * y[j]
+		}
+	}
+	return x, true
+}
+
+func relativeResidual(squareMatrix *matrix.SquareMatrix, eigenvalue complex128, vector []complex128) float64 {
+	image := make([]complex128, len(vector))
+	for i, row := range squareMatrix.Data {
+		for j, v := range row {
+			image[i] += complex(v, 0) * vector[j]
+		}
+		image[i] -= eigenvalue * vector[i]
+	}
+
+	denominator := utils.FrobeniusNorm(squareMatrix) * complexVectorNorm(vector)
+	if denominator == 0 {
+		return math.Inf(1)
+	}
+	return complexVectorNorm(image) / denominator
+}
+
+// inverse iteration vector for the real eigenvalue is real up to the common phase
+func realInverseIteration(squareMatrix *matrix.SquareMatrix, eigenvalue float64) []complex128 {
+	vector := inverseIteration(squareMatrix, complex(eigenvalue, 0), false)
+
+	maxInd := 0
+	for i := range vector {
+		if cmplx.Abs(vector[i]) > cmplx.Abs(vector[maxInd]) {
+			maxInd = i
+		}
+	}
+
+	phase := vector[maxInd] / complex(cmplx.Abs(vector[maxInd]), 0)
+	result := make([]complex128, len(vector))
+	for i := range vector {
+		result[i] = complex(real(vector[i]/phase), 0)
+	}
+	return result
+}
+
+// eigenvectors for all complex roots of every Frobenius block polynomial (the whole spectrum,
+// complex eigenvalues have complex eigenvectors), they are recovered even if there was a block split
+// in Danilevskii method, false if the roots of one of the blocks didn't converge (they are rough then)
+func FindEigenvectorsDanilevskii(squareMatrix *matrix.SquareMatrix) ([]*DanilevskiiEigenvector, bool) {
+	form := FindFrobeniusForm(squareMatrix)
+
+	var result []*DanilevskiiEigenvector
+	converged := true
+	for block := range form.Blocks {
+		roots, _, blockConverged := FindComplexPolynomialRoots(form.Blocks[block].Polynomial)
+		converged = converged && blockConverged
+
+		for _, eigenvalue := range roots {
+			vector, ok := form.blockEigenvector(block, eigenvalue)
+			fallback := !ok || relativeResidual(squareMatrix, eigenvalue, vector) > EIGENVECTOR_RESIDUAL_THRESHOLD
+			if fallback && imag(eigenvalue) == 0 {
+				vector = realInverseIteration(squareMatrix, real(eigenvalue))
+			} else if fallback {
+				vector = inverseIteration(squareMatrix, eigenvalue, false)
+			}
+
+			result = append(result, &DanilevskiiEigenvector{
+				Eigenvector:      Eigenvector{Value: eigenvalue, Vector: vector},
+				Block:            block,
+				InverseIteration: fallback,
+			})
+		}
+	}
+	return result, converged
+}
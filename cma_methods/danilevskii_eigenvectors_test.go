@@ -0,0 +1,46 @@
+package cma_methods
+
+import (
+	"cma-lab-go/matrix"
+	"testing"
+)
+
+func TestFindEigenvectorsDanilevskii(t *testing.T) {
+	tests := []struct {
+		name string
+		data [][]float64
+	}{
+		{"rotation", [][]float64{{0, -1}, {1, 0}}},
+		{"cyclic permutation", cyclicPermutation(3)},
+		{"cyclic permutation 4x4", cyclicPermutation(4)},
+		{"sampleA", [][]float64{{-24, 0, 25}, {25, 1, -25}, {-50, 0, 51}}},
+		{"block split", [][]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 2}}},
+		{"complex pair and real", [][]float64{{1, -2, 0}, {2, 1, 0}, {1, 1, 3}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			squareMatrix, err := matrix.NewSquareMatrix(test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, _, _, err := SolveQR(squareMatrix)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			eigenvectors, converged := FindEigenvectorsDanilevskii(squareMatrix)
+			if !converged {
+				t.Fatal("roots didn't converge")
+			}
+			var eigenvalues []complex128
+			for _, eigenvector := range eigenvectors {
+				eigenvalues = append(eigenvalues, eigenvector.Value)
+				if residual := relativeResidual(squareMatrix, eigenvector.Value, eigenvector.Vector); residual > 1e-8 {
+					t.Errorf("residual of eigenvalue %v is %v", eigenvector.Value, residual)
+				}
+			}
+			checkComplexValues(t, eigenvalues, expected, 1e-7)
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package cma_methods
+
+import (
+	"cma-lab-go/matrix"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// exact polynomial with rational coefficients, stored from the lowest degree
+type RationalPolynomial []*big.Rat
+
+func toRationalMatrix(squareMatrix *matrix.SquareMatrix) ([][]*big.Rat, error) {
+	size := len(squareMatrix.Data)
+	result := make([][]*big.Rat, size)
+	for i := 0; i < size; i++ {
+		result[i] = make([]*big.Rat, size)
+		for j := 0; j < size; j++ {
+			// every finite float64 is a rational number
+			result[i][j] = new(big.Rat).SetFloat64(squareMatrix.Data[i][j])
+			if result[i][j] == nil {
+				return nil, fmt.Errorf("element [%v][%v] is not finite", i, j)
+			}
+		}
+	}
+	return result, nil
+}
+
+// exact det(A - lambda * I) by the division-free Berkowitz algorithm
+// A = [[a, R], [C, A_1]] => p_A = T * p_{A_1}, where T is lower triangular Toeplitz matrix
+// with the first column (1, -a, -R * C, -R * A_1 * C, ..., -R * A_1^{n-2} * C)
+func ExactCharacteristicPolynomial(squareMatrix *matrix.SquareMatrix) (RationalPolynomial, error) {
+	a, err := toRationalMatrix(squareMatrix)
+	if err != nil {
+		return nil, err
+	}
+
+	size := len(a)
+	if size == 0 {
+		return RationalPolynomial{big.NewRat(1, 1)}, nil
+	}
+
+	// coefficients of det(lambda * I - A_k) from the highest degree
+	p := []*big.Rat{big.NewRat(1, 1), new(big.Rat).Neg(a[size-1][size-1])}
+	for k := size - 2; k >= 0; k-- {
+		m := size - k - 1
+
+		toeplitz := make([]*big.Rat, m+2)
+		toeplitz[0] = big.NewRat(1, 1)
+		toeplitz[1] = new(big.Rat).Neg(a[k][k])
+
+		// v = A_1^j * C
+		v := make([]*big.Rat, m)
+		for i := 0; i < m; i++ {
+			v[i] = new(big.Rat).Set(a[k+1+i][k])
+		}
+		for j := 0; j < m; j++ {
+			sum := new(big.Rat)
+			for i := 0; i < m; i++ {
+				sum.Add(sum, new(big.Rat).Mul(a[k][k+1+i], v[i]))
+			}
+			toeplitz[j+2] = sum.Neg(sum)
+
+			next := make([]*big.Rat, m)
+			for i := 0; i < m; i++ {
+				next[i] = new(big.Rat)
+				for l := 0; l < m; l++ {
+					next[i].Add(next[i], new(big.Rat).Mul(a[k+1+i][k+1+l], v[l]))
+				}
+			}
+			v = next
+		}
+
+		next := make([]*big.Rat, m+2)
+		for i := 0; i < m+2; i++ {
+			next[i] = new(big.Rat)
+			for j := 0; j <= i && j < len(p); j++ {
+				next[i].Add(next[i], new(big.Rat).Mul(toeplitz[i-j], p[j]))
+			}
+		}
+		p = next
+	}
+
+	// reverse the order and change the sign: det(A - lambda * I) = (-1)^n * det(lambda * I - A)
+	result := make(RationalPolynomial, size+1)
+	for i := 0; i <= size; i++ {
+		result[i] = p[size-i]
+		if size%2 == 1 {
+			result[i].Neg(result[i])
+		}
+	}
+	return result, nil
+}
+
+// nearest float64 coefficients
+func (polynomial RationalPolynomial) Float() Polynomial {
+	result := make(Polynomial, len(polynomial))
+	for i, v := range polynomial {
+		result[i], _ = v.Float64()
+	}
+	return result
+}
+
+// same form as Polynomial.String, so the exact polynomial can be compared with the computed ones,
+// fractions of the non-constant terms are parenthesized: -x^3 + (1/2)x^2 - 3
+func (polynomial RationalPolynomial) String() string {
+	var builder strings.Builder
+	for i := len(polynomial) - 1; i >= 0; i-- {
+		v := polynomial[i]
+		if v.Sign() == 0 {
+			continue
+		}
+
+		if builder.Len() == 0 {
+			if v.Sign() < 0 {
+				builder.WriteString("-")
+			}
+		} else if v.Sign() < 0 {
+			builder.WriteString(" - ")
+		} else {
+			builder.WriteString(" + ")
+		}
+
+		abs := new(big.Rat).Abs(v)
+		switch {
+		case i == 0 || abs.IsInt() && abs.Num().Cmp(big.NewInt(1)) != 0:
+			builder.WriteString(abs.RatString())
+		case !abs.IsInt():
+			builder.WriteString(fmt.Sprintf("(%v)", abs.RatString()))
+		}
+		switch i {
+		case 0:
+		case 1:
+			builder.WriteString("x")
+		default:
+			builder.WriteString(fmt.Sprintf("x^%v", i))
+		}
+	}
+	if builder.Len() == 0 {
+		return "0"
+	}
+	return builder.String()
+}
+
+// max |a_i - b_i| / max(1, |b_i|), b is the exact polynomial
+func ExactDiscrepancy(polynomial Polynomial, exact RationalPolynomial) float64 {
+	var discrepancy float64 = 0
+	size := int(math.Max(float64(len(polynomial)), float64(len(exact))))
+	for i := 0; i < size; i++ {
+		difference := new(big.Rat)
+		if i < len(polynomial) {
+			difference.SetFloat64(polynomial[i])
+		}
+
+		var scale float64 = 1
+		if i < len(exact) {
+			difference.Sub(difference, exact[i])
+			value, _ := exact[i].Float64()
+			scale = math.Max(1, math.Abs(value))
+		}
+
+		value, _ := difference.Float64()
+		discrepancy = math.Max(discrepancy, math.Abs(value)/scale)
+	}
+	return discrepancy
+}
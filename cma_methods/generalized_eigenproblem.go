@@ -0,0 +1,80 @@
+package cma_methods
+
+import (
+	"cma-lab-go/matrix"
+	"cma-lab-go/utils"
+	"fmt"
+	"math"
+)
+
+// symmetric-definite problem A * x = lambda * B * x,
+// A is symmetric, B is symmetric positive definite
+// B = L * L^T => (L^{-1} * A * L^{-T}) * y = lambda * y, x = L^{-T} * y
+// returns eigenvalues, B-orthonormal eigenvectors and number of Jacobi sweeps,
+// error, if the matrices aren't symmetric, B isn't positive definite or Jacobi sweeps don't converge
+func SolveSymmetricGeneralized(lhs, rhs *matrix.SquareMatrix) ([]float64, [][]float64, int, error) {
+	size := len(lhs.Data)
+	if size != len(rhs.Data) {
+		return nil, nil, 0, fmt.Errorf("inconsistent matrices sizes")
+	}
+
+	lhsThreshold, rhsThreshold := ZERO_THRESHOLD*utils.FrobeniusNorm(lhs), ZERO_THRESHOLD*utils.FrobeniusNorm(rhs)
+	for i := 0; i < size; i++ {
+		for j := 0; j < i; j++ {
+			if math.Abs(lhs.Data[i][j]-lhs.Data[j][i]) > lhsThreshold ||
+				math.Abs(rhs.Data[i][j]-rhs.Data[j][i]) > rhsThreshold {
+				return nil, nil, 0, fmt.Errorf("matrices are not symmetric")
+			}
+		}
+	}
+
+	lower, err := CholeskyDecomposition(rhs)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	// X = L^{-1} * A, columns are solved one by one
+	buf := make([][]float64, size)
+	column := make([]float64, size)
+	for j := 0; j < size; j++ {
+		for i := 0; i < size; i++ {
+			column[i] = lhs.Data[i][j]
+		}
+		buf[j] = solveLowerTriangular(lower, column) // j-th row of X^T = A * L^{-T}
+	}
+
+	// C = L^{-1} * (A * L^{-T})
+	reduced := make([][]float64, size)
+	for i := 0; i < size; i++ {
+		reduced[i] = make([]float64, size)
+	}
+	for j := 0; j < size; j++ {
+		for i := 0; i < size; i++ {
+			column[i] = buf[i][j]
+		}
+		solved := solveLowerTriangular(lower, column)
+		for i := 0; i < size; i++ {
+			reduced[i][j] = solved[i]
+		}
+	}
+
+	// get rid of rounding asymmetry
+	for i := 0; i < size; i++ {
+		for j := 0; j < i; j++ {
+			middle := (reduced[i][j] + reduced[j][i]) / 2
+			reduced[i][j], reduced[j][i] = middle, middle
+		}
+	}
+
+	reducedMatrix, _ := matrix.NewSquareMatrix(reduced)
+	eigenvalues, eigenvectors, sweeps, converged := SolveJacobi(reducedMatrix)
+	if !converged {
+		return nil, nil, sweeps, fmt.Errorf("jacobi method didn't converge in %v sweeps", sweeps)
+	}
+
+	for i := range eigenvectors {
+		eigenvectors[i] = solveLowerTransposed(lower, eigenvectors[i])
+	}
+
+	return eigenvalues, eigenvectors, sweeps, nil
+}
@@ -0,0 +1,73 @@
+package cma_methods
+
+import (
+	"cma-lab-go/matrix"
+	"cma-lab-go/utils"
+	"math"
+)
+
+const JACOBI_MAX_SWEEPS = 100
+
+func offDiagonalNorm(squareMatrix *matrix.SquareMatrix) float64 {
+	size := len(squareMatrix.Data)
+
+	var sum float64 = 0
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			if i != j {
+				sum += squareMatrix.Data[i][j] * squareMatrix.Data[i][j]
+			}
+		}
+	}
+	return math.Sqrt(sum)
+}
+
+// cyclic Jacobi method for symmetric matrices
+// returns eigenvalues, eigenvectors (orthonormal, eigenvectors[i] matches eigenvalues[i]),
+// number of sweeps and whether they converged in JACOBI_MAX_SWEEPS (otherwise eigenpairs are rough)
+func SolveJacobi(squareMatrixOriginal *matrix.SquareMatrix) ([]float64, [][]float64, int, bool) {
+	size := len(squareMatrixOriginal.Data)
+	squareMatrix := squareMatrixOriginal.Copy()
+	transform := utils.MakeIdentity(size)
+	// relative to ||A|| (an absolute one would stop at once for the matrices with small elements)
+	tolerance := ZERO_THRESHOLD * utils.FrobeniusNorm(squareMatrix)
+
+	sweeps := 0
+	for ; sweeps < JACOBI_MAX_SWEEPS && offDiagonalNorm(squareMatrix) > tolerance; sweeps++ {
+		for p := 0; p < size-1; p++ {
+			for q := p + 1; q < size; q++ {
+				if math.Abs(squareMatrix.Data[p][q]) < ZERO_THRESHOLD*tolerance {
+					continue
+				}
+
+				// choose the smaller rotation angle
+				theta := (squareMatrix.Data[q][q] - squareMatrix.Data[p][p]) / (2 * squareMatrix.Data[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				cos := 1 / math.Sqrt(t*t+1)
+				sin := t * cos
+
+				// P^T * A * P
+				rotateLeft(squareMatrix, p, q, cos, sin)
+				rotateRight(squareMatrix, p, q, cos, -sin)
+				rotateRight(transform, p, q, cos, -sin)
+			}
+		}
+	}
+
+	eigenvalues := make([]float64, 0, size)
+	eigenvectors := make([][]float64, 0, size)
+	for j := 0; j < size; j++ {
+		eigenvalues = append(eigenvalues, squareMatrix.Data[j][j])
+
+		vector := make([]float64, 0, size)
+		for k := 0; k < size; k++ {
+			vector = append(vector, transform.Data[k][j])
+		}
+		eigenvectors = append(eigenvectors, vector)
+	}
+
+	return eigenvalues, eigenvectors, sweeps, offDiagonalNorm(squareMatrix) <= tolerance
+}
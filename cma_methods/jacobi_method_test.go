@@ -0,0 +1,53 @@
+package cma_methods
+
+import (
+	"cma-lab-go/utils"
+	"math"
+	"testing"
+)
+
+func TestSolveJacobi(t *testing.T) {
+	sampleB := [][]float64{{3, 4, 1}, {4, 5, 2}, {1, 2, 7}}
+	tests := []struct {
+		name  string
+		data  [][]float64
+		scale float64
+	}{
+		{"sampleB", sampleB, 1},
+		{"sampleB * 1e-11", sampleB, 1e-11},
+		{"sampleB * 1e11", sampleB, 1e11},
+		{"diagonal", [][]float64{{2, 0}, {0, -1}}, 1},
+		{"zero", [][]float64{{0, 0}, {0, 0}}, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			squareMatrix := scaledMatrix(test.data, test.scale)
+			expected, _, _, err := SolveQR(squareMatrix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			eigenvalues, eigenvectors, sweeps, converged := SolveJacobi(squareMatrix)
+			if !converged {
+				t.Fatalf("didn't converge in %v sweeps", sweeps)
+			}
+
+			// A * x = lambda * x up to the rounding errors of ||A||
+			norm := utils.FrobeniusNorm(squareMatrix)
+			actual := make([]complex128, 0, len(eigenvalues))
+			for i, eigenvalue := range eigenvalues {
+				actual = append(actual, complex(eigenvalue, 0))
+				for row := range squareMatrix.Data {
+					sum := 0.0
+					for j, v := range squareMatrix.Data[row] {
+						sum += v * eigenvectors[i][j]
+					}
+					if residual := math.Abs(sum - eigenvalue*eigenvectors[i][row]); residual > 1e-10*norm {
+						t.Errorf("residual of eigenvalue %v is %v", eigenvalue, residual)
+					}
+				}
+			}
+			checkComplexValues(t, actual, expected, 1e-10)
+		})
+	}
+}
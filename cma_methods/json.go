@@ -0,0 +1,348 @@
+package cma_methods
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// complex numbers are marshalled as {"re": ..., "im": ...}
+type ComplexJSON struct {
+	Re float64 `json:"re"`
+	Im float64 `json:"im"`
+}
+
+func NewComplexJSON(value complex128) ComplexJSON {
+	return ComplexJSON{Re: real(value), Im: imag(value)}
+}
+
+func (value ComplexJSON) Complex() complex128 {
+	return complex(value.Re, value.Im)
+}
+
+func toComplexJSON(values []complex128) []ComplexJSON {
+	if values == nil {
+		return nil
+	}
+	result := make([]ComplexJSON, 0, len(values))
+	for _, v := range values {
+		result = append(result, NewComplexJSON(v))
+	}
+	return result
+}
+
+func fromComplexJSON(values []ComplexJSON) []complex128 {
+	if values == nil {
+		return nil
+	}
+	result := make([]complex128, 0, len(values))
+	for _, v := range values {
+		result = append(result, v.Complex())
+	}
+	return result
+}
+
+// JSON has no infinities and NaN, they are marshalled as null
+func finiteOrNil(value float64) *float64 {
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return nil
+	}
+	return &value
+}
+
+func infiniteIfNil(value *float64) float64 {
+	if value == nil {
+		return math.Inf(1)
+	}
+	return *value
+}
+
+func (eigenvalueCase EigenvalueCase) MarshalJSON() ([]byte, error) {
+	return json.Marshal(eigenvalueCase.String())
+}
+
+func (eigenvalueCase *EigenvalueCase) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	for _, value := range []EigenvalueCase{
+		REAL_EIGENVALUE_CASE, OPPOSITE_PAIRED_EIGENVALUES_CASE, COMPLEX_EIGENVALUES_CASE, STUCK_CASE,
+	} {
+		if value.String() == name {
+			*eigenvalueCase = value
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown eigenvalue case %q", name)
+}
+
+type eigenvectorJSON struct {
+	Value  ComplexJSON
+	Vector []ComplexJSON
+}
+
+func (eigenvector Eigenvector) MarshalJSON() ([]byte, error) {
+	return json.Marshal(eigenvectorJSON{
+		Value:  NewComplexJSON(eigenvector.Value),
+		Vector: toComplexJSON(eigenvector.Vector),
+	})
+}
+
+func (eigenvector *Eigenvector) UnmarshalJSON(data []byte) error {
+	var value eigenvectorJSON
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	eigenvector.Value = value.Value.Complex()
+	eigenvector.Vector = fromComplexJSON(value.Vector)
+	return nil
+}
+
+// the promoted methods of Eigenvector would drop the block fields
+func (eigenvector DanilevskiiEigenvector) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		eigenvectorJSON
+		Block            int
+		InverseIteration bool
+	}{
+		eigenvectorJSON: eigenvectorJSON{
+			Value:  NewComplexJSON(eigenvector.Value),
+			Vector: toComplexJSON(eigenvector.Vector),
+		},
+		Block:            eigenvector.Block,
+		InverseIteration: eigenvector.InverseIteration,
+	})
+}
+
+func (eigenvector *DanilevskiiEigenvector) UnmarshalJSON(data []byte) error {
+	var value struct {
+		eigenvectorJSON
+		Block            int
+		InverseIteration bool
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	eigenvector.Value = value.Value.Complex()
+	eigenvector.Vector = fromComplexJSON(value.Vector)
+	eigenvector.Block = value.Block
+	eigenvector.InverseIteration = value.InverseIteration
+	return nil
+}
+
+func (eigenvalue GeneralizedEigenvalue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Alpha    ComplexJSON
+		Beta     ComplexJSON
+		Infinite bool
+	}{
+		Alpha:    NewComplexJSON(eigenvalue.Alpha),
+		Beta:     NewComplexJSON(eigenvalue.Beta),
+		Infinite: eigenvalue.Infinite,
+	})
+}
+
+func (eigenvalue *GeneralizedEigenvalue) UnmarshalJSON(data []byte) error {
+	var value struct {
+		Alpha    ComplexJSON
+		Beta     ComplexJSON
+		Infinite bool
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	eigenvalue.Alpha = value.Alpha.Complex()
+	eigenvalue.Beta = value.Beta.Complex()
+	eigenvalue.Infinite = value.Infinite
+	return nil
+}
+
+type eigenvalueConditionJSON struct {
+	Value           ComplexJSON
+	Right           []ComplexJSON
+	Left            []ComplexJSON
+	Condition       *float64
+	Separation      *float64
+	VectorCondition *float64
+	IllConditioned  bool
+}
+
+func (condition EigenvalueCondition) MarshalJSON() ([]byte, error) {
+	return json.Marshal(eigenvalueConditionJSON{
+		Value:           NewComplexJSON(condition.Value),
+		Right:           toComplexJSON(condition.Right),
+		Left:            toComplexJSON(condition.Left),
+		Condition:       finiteOrNil(condition.Condition),
+		Separation:      finiteOrNil(condition.Separation),
+		VectorCondition: finiteOrNil(condition.VectorCondition),
+		IllConditioned:  condition.IllConditioned,
+	})
+}
+
+func (condition *EigenvalueCondition) UnmarshalJSON(data []byte) error {
+	var value eigenvalueConditionJSON
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*condition = EigenvalueCondition{
+		Value:           value.Value.Complex(),
+		Right:           fromComplexJSON(value.Right),
+		Left:            fromComplexJSON(value.Left),
+		Condition:       infiniteIfNil(value.Condition),
+		Separation:      infiniteIfNil(value.Separation),
+		VectorCondition: infiniteIfNil(value.VectorCondition),
+		IllConditioned:  value.IllConditioned,
+	}
+	return nil
+}
+
+func (method CharacteristicPolynomialMethod) MarshalJSON() ([]byte, error) {
+	return json.Marshal(method.String())
+}
+
+func (method *CharacteristicPolynomialMethod) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	for _, value := range []CharacteristicPolynomialMethod{
+		DANILEVSKII_METHOD, LEVERRIER_FADDEEV_METHOD, KRYLOV_METHOD, HESSENBERG_METHOD,
+	} {
+		if value.String() == name {
+			*method = value
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown characteristic polynomial method %q", name)
+}
+
+// errors are marshalled as their messages
+type polynomialDiscrepancyJSON struct {
+	Method      CharacteristicPolynomialMethod
+	Polynomial  Polynomial
+	Err         *string
+	Discrepancy *float64
+}
+
+func (discrepancy PolynomialDiscrepancy) MarshalJSON() ([]byte, error) {
+	var err *string
+	if discrepancy.Err != nil {
+		message := discrepancy.Err.Error()
+		err = &message
+	}
+	return json.Marshal(polynomialDiscrepancyJSON{
+		Method:      discrepancy.Method,
+		Polynomial:  discrepancy.Polynomial,
+		Err:         err,
+		Discrepancy: finiteOrNil(discrepancy.Discrepancy),
+	})
+}
+
+func (discrepancy *PolynomialDiscrepancy) UnmarshalJSON(data []byte) error {
+	var value polynomialDiscrepancyJSON
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*discrepancy = PolynomialDiscrepancy{
+		Method:      value.Method,
+		Polynomial:  value.Polynomial,
+		Discrepancy: infiniteIfNil(value.Discrepancy),
+	}
+	if value.Err != nil {
+		discrepancy.Err = errors.New(*value.Err)
+	}
+	return nil
+}
+
+// results of the solvers, which return several values:
+// SolveQR, SolveQRBalanced, SolveJacobi, SolveSymmetricGeneralized
+// eigenvectors are nil for complex eigenvalues (see SolveQR)
+// conditions are nil unless requested (see SolveQRWithConditions)
+type EigenResult struct {
+	Eigenvalues  []complex128
+	Eigenvectors [][]float64
+	Iterations   int
+	Conditions   []*EigenvalueCondition
+}
+
+func NewRealEigenResult(eigenvalues []float64, eigenvectors [][]float64, iterations int) *EigenResult {
+	converted := make([]complex128, 0, len(eigenvalues))
+	for _, v := range eigenvalues {
+		converted = append(converted, complex(v, 0))
+	}
+	return &EigenResult{Eigenvalues: converted, Eigenvectors: eigenvectors, Iterations: iterations}
+}
+
+type eigenResultJSON struct {
+	Eigenvalues  []ComplexJSON
+	Eigenvectors [][]float64
+	Iterations   int
+	Conditions   []*EigenvalueCondition `json:",omitempty"`
+}
+
+func (result EigenResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(eigenResultJSON{
+		Eigenvalues:  toComplexJSON(result.Eigenvalues),
+		Eigenvectors: result.Eigenvectors,
+		Iterations:   result.Iterations,
+		Conditions:   result.Conditions,
+	})
+}
+
+func (result *EigenResult) UnmarshalJSON(data []byte) error {
+	var value eigenResultJSON
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*result = EigenResult{
+		Eigenvalues:  fromComplexJSON(value.Eigenvalues),
+		Eigenvectors: value.Eigenvectors,
+		Iterations:   value.Iterations,
+		Conditions:   value.Conditions,
+	}
+	return nil
+}
+
+// result of FindMaxEigenvalues
+type PowerMethodResult struct {
+	Eigenvectors []*Eigenvector
+	Case         EigenvalueCase
+	Iterations   int
+}
+
+// result of the root finders returning complex roots
+// (FindComplexPolynomialRoots, FindPolynomialRootsCompanion, ...)
+type ComplexRootsResult struct {
+	Roots      []complex128
+	Iterations int
+	Converged  bool
+}
+
+type complexRootsResultJSON struct {
+	Roots      []ComplexJSON
+	Iterations int
+	Converged  bool
+}
+
+func (result ComplexRootsResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(complexRootsResultJSON{
+		Roots:      toComplexJSON(result.Roots),
+		Iterations: result.Iterations,
+		Converged:  result.Converged,
+	})
+}
+
+func (result *ComplexRootsResult) UnmarshalJSON(data []byte) error {
+	var value complexRootsResultJSON
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*result = ComplexRootsResult{
+		Roots:      fromComplexJSON(value.Roots),
+		Iterations: value.Iterations,
+		Converged:  value.Converged,
+	}
+	return nil
+}
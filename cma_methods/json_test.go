@@ -0,0 +1,98 @@
+package cma_methods
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{"eigen result", &EigenResult{
+			Eigenvalues:  []complex128{2, complex(1, -3)},
+			Eigenvectors: [][]float64{{1, 0}, nil},
+			Iterations:   7,
+		}},
+		{"eigen result with conditions", &EigenResult{
+			Eigenvalues:  []complex128{1},
+			Eigenvectors: [][]float64{{1}},
+			Conditions: []*EigenvalueCondition{{
+				Value:           1,
+				Right:           []complex128{1},
+				Left:            []complex128{complex(0, 1)},
+				Condition:       1,
+				Separation:      math.Inf(1),
+				VectorCondition: 0,
+			}},
+		}},
+		{"power method result", &PowerMethodResult{
+			Eigenvectors: []*Eigenvector{{Value: complex(0, 2), Vector: []complex128{1, complex(0, -1)}}},
+			Case:         COMPLEX_EIGENVALUES_CASE,
+			Iterations:   42,
+		}},
+		{"stuck power method", &PowerMethodResult{Case: STUCK_CASE, Iterations: 100}},
+		{"complex roots", &ComplexRootsResult{Roots: []complex128{complex(1, 1), complex(1, -1)}, Iterations: 5, Converged: true}},
+		{"discrepancy", &PolynomialDiscrepancy{Method: KRYLOV_METHOD, Polynomial: Polynomial{1, -2, 1}, Discrepancy: 1e-12}},
+		{"failed discrepancy", &PolynomialDiscrepancy{
+			Method:      LEVERRIER_FADDEEV_METHOD,
+			Err:         fmt.Errorf("krylov vectors are linearly dependent"),
+			Discrepancy: math.Inf(1),
+		}},
+		{"danilevskii eigenvectors", &[]*DanilevskiiEigenvector{
+			{Eigenvector: Eigenvector{Value: 3, Vector: []complex128{1, 1}}, Block: 1, InverseIteration: true},
+		}},
+		{"generalized eigenvalue", &GeneralizedEigenvalue{Alpha: complex(1, 2), Beta: 0, Infinite: true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := json.Marshal(test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded := reflect.New(reflect.TypeOf(test.value).Elem())
+			if err := json.Unmarshal(data, decoded.Interface()); err != nil {
+				t.Fatalf("unmarshal of %s: %v", data, err)
+			}
+			if !reflect.DeepEqual(decoded.Interface(), test.value) {
+				t.Errorf("%s is decoded as %+v", data, decoded.Elem().Interface())
+			}
+		})
+	}
+}
+
+func TestJSONEnums(t *testing.T) {
+	for _, value := range []EigenvalueCase{
+		REAL_EIGENVALUE_CASE, OPPOSITE_PAIRED_EIGENVALUES_CASE, COMPLEX_EIGENVALUES_CASE, STUCK_CASE,
+	} {
+		data, _ := json.Marshal(value)
+		var decoded EigenvalueCase
+		if err := json.Unmarshal(data, &decoded); err != nil || decoded != value {
+			t.Errorf("%s is decoded as %v (%v)", data, decoded, err)
+		}
+	}
+
+	for _, value := range []CharacteristicPolynomialMethod{
+		DANILEVSKII_METHOD, LEVERRIER_FADDEEV_METHOD, KRYLOV_METHOD, HESSENBERG_METHOD,
+	} {
+		data, _ := json.Marshal(value)
+		var decoded CharacteristicPolynomialMethod
+		if err := json.Unmarshal(data, &decoded); err != nil || decoded != value {
+			t.Errorf("%s is decoded as %v (%v)", data, decoded, err)
+		}
+	}
+
+	var eigenvalueCase EigenvalueCase
+	if err := json.Unmarshal([]byte(`"unknown"`), &eigenvalueCase); err == nil {
+		t.Errorf("unknown eigenvalue case is decoded")
+	}
+	var method CharacteristicPolynomialMethod
+	if err := json.Unmarshal([]byte(`"Newton"`), &method); err == nil {
+		t.Errorf("unknown method is decoded")
+	}
+}
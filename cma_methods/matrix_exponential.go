@@ -0,0 +1,141 @@
+package cma_methods
+
+import (
+	"cma-lab-go/matrix"
+	"cma-lab-go/utils"
+	"math"
+)
+
+// Pade approximants degrees and maximal 1-norms they are used for
+// (N. Higham, The scaling and squaring method for the matrix exponential revisited)
+var padeDegrees = []int{3, 5, 7, 9, 13}
+var padeThetas = []float64{
+	1.495585217958292e-2, 2.539398330063230e-1, 9.504178996162932e-1,
+	2.097847961257068e0, 5.371920351148152e0,
+}
+var padeCoefficients = map[int][]float64{
+	3: {120, 60, 12, 1},
+	5: {30240, 15120, 3360, 420, 30, 1},
+	7: {17297280, 8648640, 1995840, 277200, 25200, 1512, 56, 1},
+	9: {17643225600, 8821612800, 2075673600, 302702400, 30270240, 2162160, 110880, 3960, 90, 1},
+	13: {64764752532480000, 32382376266240000, 7771770303897600, 1187353796428800,
+		129060195264000, 10559470521600, 670442572800, 33522128640, 1323241920,
+		40840800, 960960, 16380, 182, 1},
+}
+
+// sum of coefficients[k] * matrices[k]
+func linearCombination(coefficients []float64, matrices []*matrix.SquareMatrix) *matrix.SquareMatrix {
+	size := len(matrices[0].Data)
+	result := make([][]float64, size)
+	for i := 0; i < size; i++ {
+		result[i] = make([]float64, size)
+		for k := range matrices {
+			if coefficients[k] == 0 {
+				continue
+			}
+			for j := 0; j < size; j++ {
+				result[i][j] += coefficients[k] * matrices[k].Data[i][j]
+			}
+		}
+	}
+	return &matrix.SquareMatrix{Data: result}
+}
+
+// numerator and denominator of the Pade approximant are V + U and V - U
+func padeTerms(squareMatrix *matrix.SquareMatrix, degree int) (*matrix.SquareMatrix, *matrix.SquareMatrix) {
+	size := len(squareMatrix.Data)
+	b := padeCoefficients[degree]
+
+	identity := utils.MakeIdentity(size)
+	a2, _ := matrix.MultiplyMatrices(squareMatrix, squareMatrix)
+
+	if degree == 13 {
+		a4, _ := matrix.MultiplyMatrices(a2, a2)
+		a6, _ := matrix.MultiplyMatrices(a4, a2)
+
+		inner := linearCombination([]float64{b[13], b[11], b[9]}, []*matrix.SquareMatrix{a6, a4, a2})
+		inner, _ = matrix.MultiplyMatrices(a6, inner)
+		inner = linearCombination([]float64{1, b[7], b[5], b[3], b[1]},
+			[]*matrix.SquareMatrix{inner, a6, a4, a2, identity})
+		u, _ := matrix.MultiplyMatrices(squareMatrix, inner)
+
+		inner = linearCombination([]float64{b[12], b[10], b[8]}, []*matrix.SquareMatrix{a6, a4, a2})
+		inner, _ = matrix.MultiplyMatrices(a6, inner)
+		v := linearCombination([]float64{1, b[6], b[4], b[2], b[0]},
+			[]*matrix.SquareMatrix{inner, a6, a4, a2, identity})
+		return u, v
+	}
+
+	// powers[k] = A^{2k}
+	powers := []*matrix.SquareMatrix{identity, a2}
+	for len(powers) <= degree/2 {
+		next, _ := matrix.MultiplyMatrices(powers[len(powers)-1], a2)
+		powers = append(powers, next)
+	}
+
+	odd := make([]float64, 0, len(powers))
+	even := make([]float64, 0, len(powers))
+	for k := range powers {
+		odd = append(odd, b[2*k+1])
+		even = append(even, b[2*k])
+	}
+
+	u, _ := matrix.MultiplyMatrices(squareMatrix, linearCombination(odd, powers))
+	return u, linearCombination(even, powers)
+}
+
+// matrix exponential, scaling and squaring with Pade approximants
+func Expm(squareMatrix *matrix.SquareMatrix) (*matrix.SquareMatrix, error) {
+	size := len(squareMatrix.Data)
+	norm := utils.MatrixNorm1(squareMatrix)
+
+	degree, squarings := 13, 0
+	for i, theta := range padeThetas {
+		if norm <= theta {
+			degree = padeDegrees[i]
+			break
+		}
+	}
+
+	scaled := squareMatrix
+	if norm > padeThetas[len(padeThetas)-1] {
+		squarings = int(math.Ceil(math.Log2(norm / padeThetas[len(padeThetas)-1])))
+		scaled = linearCombination([]float64{math.Pow(2, -float64(squarings))},
+			[]*matrix.SquareMatrix{squareMatrix})
+	}
+
+	u, v := padeTerms(scaled, degree)
+
+	// (V - U) * X = (V + U)
+	lu, err := utils.NewLU(linearCombination([]float64{1, -1}, []*matrix.SquareMatrix{v, u}))
+	if err != nil {
+		return &matrix.SquareMatrix{}, err
+	}
+	numerator := linearCombination([]float64{1, 1}, []*matrix.SquareMatrix{v, u})
+
+	result := make([][]float64, size)
+	for i := range result {
+		result[i] = make([]float64, size)
+	}
+	column := make([]float64, size)
+	for j := 0; j < size; j++ {
+		for i := 0; i < size; i++ {
+			column[i] = numerator.Data[i][j]
+		}
+		solved := lu.Solve(column)
+		for i := 0; i < size; i++ {
+			result[i][j] = solved[i]
+		}
+	}
+
+	exponent := &matrix.SquareMatrix{Data: result}
+	for i := 0; i < squarings; i++ {
+		exponent, _ = matrix.MultiplyMatrices(exponent, exponent)
+	}
+	return exponent, nil
+}
+
+// exp(A * t), solution of x' = A * x is x(t) = exp(A * t) * x(0)
+func ExpmT(squareMatrix *matrix.SquareMatrix, t float64) (*matrix.SquareMatrix, error) {
+	return Expm(linearCombination([]float64{t}, []*matrix.SquareMatrix{squareMatrix}))
+}
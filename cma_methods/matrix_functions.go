@@ -0,0 +1,437 @@
+package cma_methods
+
+import (
+	"cma-lab-go/matrix"
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+const (
+	LOGM_MAX_SQUARE_ROOTS = 64
+	LOGM_SERIES_RADIUS    = 0.25
+	LOGM_MAX_SERIES_TERMS = 200
+)
+
+// imaginary parts of the result above this value (relative) mean that f(A) is not real
+const REAL_RESULT_THRESHOLD = 1e-8
+
+// T = G^H * T * G, Q = Q * G for G = [v, w] on the rows and columns k, k + 1,
+// v = [first, second] has to be a unit vector, w is orthogonal to it
+func rotateComplexSchur(schur, transform [][]complex128, k int, first, second complex128) {
+	size := len(schur)
+	for j := 0; j < size; j++ {
+		u, v := schur[k][j], schur[k+1][j]
+		schur[k][j] = cmplx.Conj(first)*u + cmplx.Conj(second)*v
+		schur[k+1][j] = -second*u + first*v
+	}
+	for _, m := range [][][]complex128{schur, transform} {
+		for i := 0; i < size; i++ {
+			u, v := m[i][k], m[i][k+1]
+			m[i][k] = first*u + second*v
+			m[i][k+1] = -cmplx.Conj(second)*u + cmplx.Conj(first)*v
+		}
+	}
+	schur[k+1][k] = 0
+}
+
+// complex Schur form: A = Q * T * Q^H, T is upper triangular
+// it is obtained from the real Schur form by splitting 2x2 blocks
+func complexSchurDecomposition(squareMatrix *matrix.SquareMatrix) ([][]complex128, [][]complex128, error) {
+	realSchur, realTransform, _, err := SchurDecompositionWithOptions(squareMatrix, DefaultQROptions)
+	if err != nil {
+		return nil, nil, err
+	}
+	schur, transform := toComplexMatrix(realSchur), toComplexMatrix(realTransform)
+	size := len(schur)
+	threshold := DefaultQROptions.threshold(squareMatrix)
+
+	for k := 0; k+1 < size; k++ {
+		if math.Abs(realSchur.Data[k+1][k]) <= threshold {
+			schur[k+1][k] = 0
+			continue
+		}
+
+		// eigenvector of the 2x2 block for the eigenvalue
+		a, b, c, d := schur[k][k], schur[k][k+1], schur[k+1][k], schur[k+1][k+1]
+		lambda := (a + d + cmplx.Sqrt((a-d)*(a-d)+4*b*c)) / 2
+		first, second := b, lambda-a
+		if cmplx.Abs(first) == 0 && cmplx.Abs(second) == 0 {
+			first, second = lambda-d, c
+		}
+
+		norm := complex(math.Hypot(cmplx.Abs(first), cmplx.Abs(second)), 0)
+		rotateComplexSchur(schur, transform, k, first/norm, second/norm)
+		k++
+	}
+
+	return schur, transform, nil
+}
+
+func multiplyComplexMatrices(lhs, rhs [][]complex128) [][]complex128 {
+	size := len(lhs)
+	result := make([][]complex128, size)
+	for i := 0; i < size; i++ {
+		result[i] = make([]complex128, size)
+		for k := 0; k < size; k++ {
+			if lhs[i][k] == 0 {
+				continue
+			}
+			for j := 0; j < size; j++ {
+				result[i][j] += lhs[i][k] * rhs[k][j]
+			}
+		}
+	}
+	return result
+}
+
+// Q * F * Q^H, imaginary part has to be negligible
+func fromSchurBasis(function, transform [][]complex128) (*matrix.SquareMatrix, error) {
+	size := len(function)
+	adjoint := make([][]complex128, size)
+	for i := 0; i < size; i++ {
+		adjoint[i] = make([]complex128, size)
+		for j := 0; j < size; j++ {
+			adjoint[i][j] = cmplx.Conj(transform[j][i])
+		}
+	}
+
+	product := multiplyComplexMatrices(multiplyComplexMatrices(transform, function), adjoint)
+	scale := math.Max(frobeniusNorm(product), 1)
+
+	data := make([][]float64, size)
+	for i := 0; i < size; i++ {
+		data[i] = make([]float64, size)
+		for j := 0; j < size; j++ {
+			if math.Abs(imag(product[i][j])) > REAL_RESULT_THRESHOLD*scale {
+				return &matrix.SquareMatrix{}, fmt.Errorf("function of the matrix is not real")
+			}
+			data[i][j] = real(product[i][j])
+		}
+	}
+	return matrix.NewSquareMatrix(data)
+}
+
+// analytic function given with its derivatives: f(k, z) is the k-th derivative at z, f(0, z) = f(z)
+// the derivatives are used only for close eigenvalues
+type AnalyticFunction func(order int, z complex128) complex128
+
+var (
+	ExpFunction AnalyticFunction = func(order int, z complex128) complex128 {
+		return cmplx.Exp(z)
+	}
+	SinFunction AnalyticFunction = func(order int, z complex128) complex128 {
+		if order%2 == 0 {
+			return []complex128{1, -1}[order/2%2] * cmplx.Sin(z)
+		}
+		return []complex128{1, -1}[order/2%2] * cmplx.Cos(z)
+	}
+	CosFunction AnalyticFunction = func(order int, z complex128) complex128 {
+		return SinFunction(order+1, z)
+	}
+)
+
+// eigenvalues closer than this value are evaluated together by the Taylor series
+// (P. Davies, N. Higham, A Schur-Parlett algorithm for computing matrix functions)
+const FUNM_CLUSTER_DELTA = 0.1
+
+// the factorials overflow after 170 terms
+const FUNM_MAX_TAYLOR_TERMS = 170
+
+// cluster index of every diagonal element, eigenvalues of different clusters are farther than delta
+func clusterEigenvalues(schur [][]complex128, delta float64) []int {
+	size := len(schur)
+	clusters := make([]int, size)
+	for i := range clusters {
+		clusters[i] = i
+	}
+
+	for i := 0; i < size; i++ {
+		for j := i + 1; j < size; j++ {
+			if clusters[i] == clusters[j] || cmplx.Abs(schur[i][i]-schur[j][j]) > delta {
+				continue
+			}
+			merged := clusters[j]
+			for k := range clusters {
+				if clusters[k] == merged {
+					clusters[k] = clusters[i]
+				}
+			}
+		}
+	}
+	return clusters
+}
+
+// reorders the Schur form by swaps of the adjacent eigenvalues, so that every cluster
+// is a contiguous block, returns the bounds [low, high) of the blocks
+func groupClusters(schur, transform [][]complex128, clusters []int) [][2]int {
+	// clusters are ordered by their first eigenvalue
+	order := make(map[int]int)
+	ranks := make([]int, len(clusters))
+	for i, cluster := range clusters {
+		if _, ok := order[cluster]; !ok {
+			order[cluster] = len(order)
+		}
+		ranks[i] = order[cluster]
+	}
+
+	for sorted := false; !sorted; {
+		sorted = true
+		for k := 0; k+1 < len(ranks); k++ {
+			if ranks[k] <= ranks[k+1] {
+				continue
+			}
+			sorted = false
+
+			// the first column of the rotation is the eigenvector of t_{k+1,k+1}
+			first, second := schur[k][k+1], schur[k+1][k+1]-schur[k][k]
+			norm := complex(math.Hypot(cmplx.Abs(first), cmplx.Abs(second)), 0)
+			a, b := schur[k][k], schur[k+1][k+1]
+			rotateComplexSchur(schur, transform, k, first/norm, second/norm)
+			schur[k][k], schur[k+1][k+1] = b, a
+			ranks[k], ranks[k+1] = ranks[k+1], ranks[k]
+		}
+	}
+
+	var blocks [][2]int
+	for low := 0; low < len(ranks); {
+		high := low + 1
+		for high < len(ranks) && ranks[high] == ranks[low] {
+			high++
+		}
+		blocks = append(blocks, [2]int{low, high})
+		low = high
+	}
+	return blocks
+}
+
+// f(T) for the diagonal block [low, high) of T by the Taylor series at the mean of its eigenvalues
+// f(T) = sum f^(k)(sigma) / k! * (T - sigma * I)^k
+func taylorBlock(schur [][]complex128, low, high int, f AnalyticFunction) ([][]complex128, error) {
+	size := high - low
+	if size == 1 {
+		return [][]complex128{{f(0, schur[low][low])}}, nil
+	}
+
+	var sigma complex128 = 0
+	for i := low; i < high; i++ {
+		sigma += schur[i][i]
+	}
+	sigma /= complex(float64(size), 0)
+
+	shifted := make([][]complex128, size)
+	power := make([][]complex128, size)
+	function := make([][]complex128, size)
+	for i := 0; i < size; i++ {
+		shifted[i] = make([]complex128, size)
+		copy(shifted[i], schur[low+i][low:high])
+		shifted[i][i] -= sigma
+		power[i] = make([]complex128, size)
+		power[i][i] = 1
+		function[i] = make([]complex128, size)
+		function[i][i] = f(0, sigma)
+	}
+
+	epsilon := math.Nextafter(1, 2) - 1
+	factorial, smallTerms := 1.0, 0
+	for k := 1; k <= FUNM_MAX_TAYLOR_TERMS; k++ {
+		power = multiplyComplexMatrices(power, shifted)
+		factorial *= float64(k)
+		coefficient := f(k, sigma) / complex(factorial, 0)
+
+		var termNorm float64 = 0
+		for i := 0; i < size; i++ {
+			for j := i; j < size; j++ {
+				term := coefficient * power[i][j]
+				function[i][j] += term
+				termNorm += real(term)*real(term) + imag(term)*imag(term)
+			}
+		}
+
+		// two small terms in a row, odd or even derivatives may vanish
+		if math.Sqrt(termNorm) <= epsilon*frobeniusNorm(function) {
+			smallTerms++
+		} else {
+			smallTerms = 0
+		}
+		if smallTerms == 2 && k >= size {
+			return function, nil
+		}
+	}
+	return nil, fmt.Errorf("taylor series didn't converge for the eigenvalues near %v", sigma)
+}
+
+// block Parlett recurrence for upper triangular T with f(T) known on the diagonal blocks:
+// F_ij * (t_jj - t_ii) = t_ij * (F_jj - F_ii) + sum_{i<k<j} (t_ik * F_kj - F_ik * t_kj)
+// it is solved only for i and j from different blocks, so that |t_jj - t_ii| > delta
+func parlett(schur [][]complex128, blocks [][2]int, f AnalyticFunction) ([][]complex128, error) {
+	size := len(schur)
+	function := make([][]complex128, size)
+	for i := 0; i < size; i++ {
+		function[i] = make([]complex128, size)
+	}
+
+	blockOf := make([]int, size)
+	for b, block := range blocks {
+		diagonal, err := taylorBlock(schur, block[0], block[1], f)
+		if err != nil {
+			return nil, err
+		}
+		for i := block[0]; i < block[1]; i++ {
+			blockOf[i] = b
+			copy(function[i][block[0]:block[1]], diagonal[i-block[0]])
+		}
+	}
+
+	for d := 1; d < size; d++ {
+		for i := 0; i+d < size; i++ {
+			j := i + d
+			if blockOf[i] == blockOf[j] {
+				continue
+			}
+
+			sum := schur[i][j] * (function[j][j] - function[i][i])
+			for k := i + 1; k < j; k++ {
+				sum += schur[i][k]*function[k][j] - function[i][k]*schur[k][j]
+			}
+			function[i][j] = sum / (schur[j][j] - schur[i][i])
+		}
+	}
+	return function, nil
+}
+
+// f(A) for the analytic function f, blocked Schur-Parlett method
+func Funm(squareMatrix *matrix.SquareMatrix, f AnalyticFunction) (*matrix.SquareMatrix, error) {
+	schur, transform, err := complexSchurDecomposition(squareMatrix)
+	if err != nil {
+		return &matrix.SquareMatrix{}, err
+	}
+	blocks := groupClusters(schur, transform, clusterEigenvalues(schur, FUNM_CLUSTER_DELTA))
+	function, err := parlett(schur, blocks, f)
+	if err != nil {
+		return &matrix.SquareMatrix{}, err
+	}
+	return fromSchurBasis(function, transform)
+}
+
+// square root of the upper triangular matrix (Bjorck-Hammarling)
+// R_ij * (R_ii + R_jj) = t_ij - sum_{i<k<j} R_ik * R_kj
+func triangularSqrt(schur [][]complex128) ([][]complex128, error) {
+	size := len(schur)
+	root := make([][]complex128, size)
+	for i := 0; i < size; i++ {
+		root[i] = make([]complex128, size)
+		root[i][i] = cmplx.Sqrt(schur[i][i])
+	}
+
+	for d := 1; d < size; d++ {
+		for i := 0; i+d < size; i++ {
+			j := i + d
+			sum := schur[i][j]
+			for k := i + 1; k < j; k++ {
+				sum -= root[i][k] * root[k][j]
+			}
+
+			denominator := root[i][i] + root[j][j]
+			if denominator == 0 {
+				if sum != 0 {
+					return nil, fmt.Errorf("square root doesn't exist")
+				}
+				continue
+			}
+			root[i][j] = sum / denominator
+		}
+	}
+	return root, nil
+}
+
+// principal square root
+func Sqrtm(squareMatrix *matrix.SquareMatrix) (*matrix.SquareMatrix, error) {
+	schur, transform, err := complexSchurDecomposition(squareMatrix)
+	if err != nil {
+		return &matrix.SquareMatrix{}, err
+	}
+	root, err := triangularSqrt(schur)
+	if err != nil {
+		return &matrix.SquareMatrix{}, err
+	}
+	return fromSchurBasis(root, transform)
+}
+
+// principal logarithm, inverse scaling and squaring:
+// log(T) = 2^s * log(T^{1/2^s}), log(I + X) is summed as a series for small X
+func Logm(squareMatrix *matrix.SquareMatrix) (*matrix.SquareMatrix, error) {
+	schur, transform, err := complexSchurDecomposition(squareMatrix)
+	if err != nil {
+		return &matrix.SquareMatrix{}, err
+	}
+	size := len(schur)
+
+	for i := 0; i < size; i++ {
+		if schur[i][i] == 0 {
+			return &matrix.SquareMatrix{}, fmt.Errorf("matrix is singular, logarithm doesn't exist")
+		}
+	}
+
+	// X = T^{1/2^s} - I
+	squareRoots := 0
+	for {
+		for i := 0; i < size; i++ {
+			schur[i][i] -= 1
+		}
+		distance := frobeniusNorm(schur)
+		for i := 0; i < size; i++ {
+			schur[i][i] += 1
+		}
+		if distance < LOGM_SERIES_RADIUS {
+			break
+		}
+		if squareRoots == LOGM_MAX_SQUARE_ROOTS {
+			return &matrix.SquareMatrix{}, fmt.Errorf("unable to reduce the matrix to the identity")
+		}
+
+		if schur, err = triangularSqrt(schur); err != nil {
+			return &matrix.SquareMatrix{}, err
+		}
+		squareRoots++
+	}
+
+	for i := 0; i < size; i++ {
+		schur[i][i] -= 1
+	}
+
+	// log(I + X) = X - X^2 / 2 + X^3 / 3 - ...
+	logarithm := make([][]complex128, size)
+	power := make([][]complex128, size)
+	for i := 0; i < size; i++ {
+		logarithm[i] = make([]complex128, size)
+		power[i] = make([]complex128, size)
+		copy(power[i], schur[i])
+	}
+
+	epsilon := math.Nextafter(1, 2) - 1
+	for k := 1; k <= LOGM_MAX_SERIES_TERMS; k++ {
+		coefficient := complex(1/float64(k), 0)
+		if k%2 == 0 {
+			coefficient = -coefficient
+		}
+		for i := 0; i < size; i++ {
+			for j := 0; j < size; j++ {
+				logarithm[i][j] += coefficient * power[i][j]
+			}
+		}
+
+		if frobeniusNorm(power)/float64(k) <= epsilon*math.Max(frobeniusNorm(logarithm), 1) {
+			break
+		}
+		power = multiplyComplexMatrices(power, schur)
+	}
+
+	scale := complex(math.Pow(2, float64(squareRoots)), 0)
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			logarithm[i][j] *= scale
+		}
+	}
+	return fromSchurBasis(logarithm, transform)
+}
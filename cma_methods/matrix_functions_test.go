@@ -0,0 +1,87 @@
+package cma_methods
+
+import (
+	"cma-lab-go/matrix"
+	"cma-lab-go/utils"
+	"math"
+	"testing"
+)
+
+func maxDifference(lhs, rhs *matrix.SquareMatrix) float64 {
+	difference := 0.0
+	for i := range lhs.Data {
+		for j := range lhs.Data[i] {
+			difference = math.Max(difference, math.Abs(lhs.Data[i][j]-rhs.Data[i][j]))
+		}
+	}
+	return difference
+}
+
+func TestFunmExpMatchesExpm(t *testing.T) {
+	tests := []struct {
+		name string
+		data [][]float64
+	}{
+		{"diagonal", [][]float64{{1, 0}, {0, -2}}},
+		{"jordan block", [][]float64{{1, 1, 0}, {0, 1, 1}, {0, 0, 1}}},
+		{"double eigenvalue", [][]float64{{3, 1}, {-1, 1}}},
+		{"close eigenvalues", [][]float64{{1, 1}, {0, 1 + 1e-9}}},
+		{"complex eigenvalues", [][]float64{{0, 1}, {-1, 0}}},
+		{"non-normal", [][]float64{{1, 2, 3}, {0, 1.05, 4}, {1e-3, 0, 2}}},
+		{"interleaved clusters", [][]float64{{1, 2, 3, 4}, {0, 5, 1, 2}, {0, 0, 1.05, 3}, {0, 0, 0, 5.02}}},
+		{"sampleA", [][]float64{{-24, 0, 25}, {25, 1, -25}, {-50, 0, 51}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			squareMatrix, err := matrix.NewSquareMatrix(test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := Expm(squareMatrix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := Funm(squareMatrix, ExpFunction)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			scale := math.Max(utils.FrobeniusNorm(expected), 1)
+			if difference := maxDifference(expected, actual); difference > 1e-9*scale {
+				t.Errorf("Funm(exp) = %v, Expm = %v, difference %v", actual.Data, expected.Data, difference)
+			}
+		})
+	}
+}
+
+func TestFunmJordanBlock(t *testing.T) {
+	jordan, _ := matrix.NewSquareMatrix([][]float64{{1, 1, 0}, {0, 1, 1}, {0, 0, 1}})
+	e := math.E
+	tests := []struct {
+		name     string
+		f        AnalyticFunction
+		expected [][]float64
+	}{
+		{"exp", ExpFunction, [][]float64{{e, e, e / 2}, {0, e, e}, {0, 0, e}}},
+		{"sin", SinFunction, [][]float64{
+			{math.Sin(1), math.Cos(1), -math.Sin(1) / 2}, {0, math.Sin(1), math.Cos(1)}, {0, 0, math.Sin(1)},
+		}},
+		{"cos", CosFunction, [][]float64{
+			{math.Cos(1), -math.Sin(1), -math.Cos(1) / 2}, {0, math.Cos(1), -math.Sin(1)}, {0, 0, math.Cos(1)},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := Funm(jordan, test.f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, _ := matrix.NewSquareMatrix(test.expected)
+			if difference := maxDifference(expected, actual); difference > 1e-12 {
+				t.Errorf("f(J) = %v, expected %v", actual.Data, test.expected)
+			}
+		})
+	}
+}
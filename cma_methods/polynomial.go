@@ -0,0 +1,275 @@
+package cma_methods
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// coefficients are stored from the lowest degree:
+// Polynomial{a0, a1, a2} = a0 + a1 * x + a2 * x^2
+type Polynomial []float64
+
+// relative threshold for zero coefficients in GCD computation
+const GCD_THRESHOLD = 1e-9
+
+// relative threshold for the rounding errors in the leading coefficients of products (see TrimLeading)
+const LEADING_THRESHOLD = 1e-9
+
+func NewPolynomial(coefficients []float64) Polynomial {
+	return Polynomial(coefficients).Trim()
+}
+
+// drops zero leading coefficients (the zero polynomial is Polynomial{0})
+func (polynomial Polynomial) Trim() Polynomial {
+	if len(polynomial) == 0 {
+		return Polynomial{0}
+	}
+
+	last := len(polynomial) - 1
+	for last > 0 && polynomial[last] == 0 {
+		last--
+	}
+	return polynomial[:last+1]
+}
+
+// degree of the zero polynomial is -1
+func (polynomial Polynomial) Degree() int {
+	trimmed := polynomial.Trim()
+	if len(trimmed) == 1 && trimmed[0] == 0 {
+		return -1
+	}
+	return len(trimmed) - 1
+}
+
+// drops leading coefficients up to threshold * max |a_i|, they are rounding errors of computed polynomials
+// (like the ones of the block products in Danilevskii method)
+func (polynomial Polynomial) TrimLeading(threshold float64) Polynomial {
+	var max float64 = 0
+	for _, v := range polynomial {
+		max = math.Max(max, math.Abs(v))
+	}
+
+	last := len(polynomial) - 1
+	for last > 0 && math.Abs(polynomial[last]) <= threshold*max {
+		last--
+	}
+	return polynomial[:last+1].Trim()
+}
+
+func (polynomial Polynomial) Leading() float64 {
+	trimmed := polynomial.Trim()
+	return trimmed[len(trimmed)-1]
+}
+
+func (polynomial Polynomial) Copy() Polynomial {
+	result := make(Polynomial, len(polynomial))
+	copy(result, polynomial)
+	return result
+}
+
+// Arithmetic
+
+func (polynomial Polynomial) Add(other Polynomial) Polynomial {
+	size := int(math.Max(float64(len(polynomial)), float64(len(other))))
+	result := make(Polynomial, size)
+	copy(result, polynomial)
+	for i, v := range other {
+		result[i] += v
+	}
+	return result.Trim()
+}
+
+func (polynomial Polynomial) Sub(other Polynomial) Polynomial {
+	return polynomial.Add(other.Scale(-1))
+}
+
+func (polynomial Polynomial) Scale(factor float64) Polynomial {
+	result := make(Polynomial, len(polynomial))
+	for i, v := range polynomial {
+		result[i] = v * factor
+	}
+	return result.Trim()
+}
+
+// exact product, only zero leading coefficients are dropped (see TrimLeading for the small ones)
+func (polynomial Polynomial) Mul(other Polynomial) Polynomial {
+	if len(polynomial) == 0 || len(other) == 0 {
+		return Polynomial{0}
+	}
+
+	result := make(Polynomial, len(polynomial)+len(other)-1)
+	for k1, v1 := range polynomial {
+		for k2, v2 := range other {
+			result[k1+k2] += v1 * v2
+		}
+	}
+	return result.Trim()
+}
+
+// long division: polynomial = quotient * divisor + remainder, deg(remainder) < deg(divisor)
+func (polynomial Polynomial) DivMod(divisor Polynomial) (Polynomial, Polynomial) {
+	divisor = divisor.Trim()
+	if divisor.Degree() == -1 {
+		panic("DivMod() is called with the zero divisor")
+	}
+
+	remainder := polynomial.Trim().Copy()
+	deg := divisor.Degree()
+	if remainder.Degree() < deg {
+		return Polynomial{0}, remainder
+	}
+
+	quotient := make(Polynomial, len(remainder)-deg)
+	leading := divisor[deg]
+	for i := len(remainder) - 1; i >= deg; i-- {
+		coefficient := remainder[i] / leading
+		quotient[i-deg] = coefficient
+		for j := 0; j <= deg; j++ {
+			remainder[i-deg+j] -= coefficient * divisor[j]
+		}
+		remainder[i] = 0
+	}
+
+	return quotient.Trim(), remainder[:deg].Trim()
+}
+
+func (polynomial Polynomial) Div(divisor Polynomial) Polynomial {
+	quotient, _ := polynomial.DivMod(divisor)
+	return quotient
+}
+
+func (polynomial Polynomial) Mod(divisor Polynomial) Polynomial {
+	_, remainder := polynomial.DivMod(divisor)
+	return remainder
+}
+
+// polynomial(inner(x))
+func (polynomial Polynomial) Compose(inner Polynomial) Polynomial {
+	result := Polynomial{0}
+	for i := len(polynomial) - 1; i >= 0; i-- {
+		result = result.Mul(inner).Add(Polynomial{polynomial[i]})
+	}
+	return result
+}
+
+// coefficients, which are small compared to the largest one, are set to zero
+func (polynomial Polynomial) chop(threshold float64) Polynomial {
+	var max float64 = 0
+	for _, v := range polynomial {
+		max = math.Max(max, math.Abs(v))
+	}
+
+	result := polynomial.Copy()
+	for i, v := range result {
+		if math.Abs(v) <= threshold*max {
+			result[i] = 0
+		}
+	}
+	return result.Trim()
+}
+
+// monic greatest common divisor, Euclidean algorithm
+// remainders coefficients below GCD_THRESHOLD (relative) are treated as zeros
+func GCD(lhs, rhs Polynomial) Polynomial {
+	lhs, rhs = lhs.chop(GCD_THRESHOLD), rhs.chop(GCD_THRESHOLD)
+	if lhs.Degree() < rhs.Degree() {
+		lhs, rhs = rhs, lhs
+	}
+
+	for rhs.Degree() != -1 {
+		// the remainder is compared with the dividend scale
+		remainder := lhs.Mod(rhs)
+		var scale float64 = 0
+		for _, v := range lhs {
+			scale = math.Max(scale, math.Abs(v))
+		}
+		for i, v := range remainder {
+			if math.Abs(v) <= GCD_THRESHOLD*scale {
+				remainder[i] = 0
+			}
+		}
+
+		lhs, rhs = rhs, remainder.Trim()
+		if rhs.Degree() != -1 {
+			rhs = rhs.Scale(1 / rhs.Leading())
+		}
+	}
+
+	if lhs.Degree() == -1 {
+		return lhs
+	}
+	return lhs.Scale(1 / lhs.Leading())
+}
+
+// Calculus
+
+func (polynomial Polynomial) Derivative() Polynomial {
+	if len(polynomial) <= 1 {
+		return Polynomial{0}
+	}
+
+	result := make(Polynomial, len(polynomial)-1)
+	for i := 1; i < len(polynomial); i++ {
+		result[i-1] = polynomial[i] * float64(i)
+	}
+	return result
+}
+
+// Horner's scheme
+func (polynomial Polynomial) Value(point float64) float64 {
+	var result float64 = 0
+	for i := len(polynomial) - 1; i >= 0; i-- {
+		result = result*point + polynomial[i]
+	}
+	return result
+}
+
+func (polynomial Polynomial) ComplexValue(point complex128) complex128 {
+	var result complex128 = 0
+	for i := len(polynomial) - 1; i >= 0; i-- {
+		result = result*point + complex(polynomial[i], 0)
+	}
+	return result
+}
+
+// Printing
+
+// -x^3 + 28x^2 - 53x + 26
+func (polynomial Polynomial) String() string {
+	trimmed := polynomial.Trim()
+	if trimmed.Degree() == -1 {
+		return "0"
+	}
+
+	var builder strings.Builder
+	for i := len(trimmed) - 1; i >= 0; i-- {
+		v := trimmed[i]
+		if v == 0 {
+			continue
+		}
+
+		if builder.Len() == 0 {
+			if v < 0 {
+				builder.WriteString("-")
+			}
+		} else if v < 0 {
+			builder.WriteString(" - ")
+		} else {
+			builder.WriteString(" + ")
+		}
+
+		abs := math.Abs(v)
+		if abs != 1 || i == 0 {
+			builder.WriteString(fmt.Sprint(abs))
+		}
+		switch i {
+		case 0:
+		case 1:
+			builder.WriteString("x")
+		default:
+			builder.WriteString(fmt.Sprintf("x^%v", i))
+		}
+	}
+	return builder.String()
+}
@@ -2,33 +2,27 @@ package cma_methods
 
 import (
 	"math"
+	"sort"
 )
 
 const NEWTON_ACCURACY = 1e-12
 
-func diff(polynomial []float64) []float64 {
-	result := make([]float64, len(polynomial) - 1)
-	for i := 1; i < len(polynomial); i++ {
-		result[i-1] = polynomial[i] * float64(i)
-	}
-	return result
-}
+// relative threshold of the polynomial value in tangent roots detection
+const TANGENT_THRESHOLD = 1e-9
 
-func value(polynomial []float64, point float64) float64 {
-	var sum float64 = polynomial[0]
-	power := point
-	for i := 1; i < len(polynomial); i++ {
-		sum += polynomial[i] * power
-		power *= point
-	}
-	return sum
-}
+// relative threshold of the polynomial value, which stops the root refinement
+const RESIDUAL_ACCURACY = 1e-15
 
-func checkFourier(polynomial []float64, point float64) bool {
-	return value(diff(diff(polynomial)), point)*value(polynomial, point) > 0
+const ROOT_MAX_ITERATIONS = 200
+
+type RootResult struct {
+	Value      float64
+	Iterations int
+	// false if neither step nor residual tolerance was reached
+	Converged bool
 }
 
-func boundRoots(polynomial []float64) (float64, float64) {
+func boundRoots(polynomial Polynomial) (float64, float64) {
 	var max float64 = 0
 	for i := 1; i < len(polynomial); i++ {
 		max = math.Max(max, math.Abs(polynomial[i]))
@@ -37,78 +31,182 @@ func boundRoots(polynomial []float64) (float64, float64) {
 	return -1 - max / high, 1 + max / high
 }
 
-func bisection(polynomial []float64, left, right float64) (float64, float64) {
-	rightSign := math.Signbit(value(polynomial, right))
-	middle := (left + right) / 2
-
-	if math.Signbit(value(polynomial, middle)) == rightSign {
-		return left, middle
+// bound of |p(x)| rounding errors: sum |a_i| * |x|^i
+func evaluationBound(polynomial Polynomial, point float64) float64 {
+	var bound float64 = 0
+	power := 1.0
+	for _, v := range polynomial {
+		bound += math.Abs(v) * power
+		power *= math.Abs(point)
 	}
-	return middle, right
+	return bound
 }
 
-func newton(polynomial, differential []float64, point float64) float64 {
-	return point - value(polynomial, point) / value(differential, point)
+// Newton-bisection hybrid: Newton step is taken only if it stays inside
+// the bracket and halves the step at least as well as the bisection
+func safeguardedNewton(polynomial, differential Polynomial, left, right float64) RootResult {
+	// p(low) < 0 < p(high)
+	low, high := left, right
+	if polynomial.Value(left) > 0 {
+		low, high = right, left
+	}
+
+	point := (left + right) / 2
+	step, prevStep := math.Abs(right-left), math.Abs(right-left)
+	for iteration := 1; iteration <= ROOT_MAX_ITERATIONS; iteration++ {
+		value, derivative := polynomial.Value(point), differential.Value(point)
+		if math.Abs(value) <= RESIDUAL_ACCURACY*evaluationBound(polynomial, point) {
+			return RootResult{Value: point, Iterations: iteration, Converged: true}
+		}
+
+		if value < 0 {
+			low = point
+		} else {
+			high = point
+		}
+
+		prev := point
+		newtonOutside := ((point-high)*derivative-value)*((point-low)*derivative-value) > 0
+		if derivative == 0 || newtonOutside || math.Abs(2*value) > math.Abs(prevStep*derivative) {
+			prevStep, step = step, (high-low)/2
+			point = low + step
+		} else {
+			prevStep, step = step, value/derivative
+			point -= step
+		}
+
+		if math.Abs(point-prev) <= NEWTON_ACCURACY*math.Max(1, math.Abs(point)) {
+			return RootResult{Value: point, Iterations: iteration, Converged: true}
+		}
+	}
+
+	return RootResult{Value: point, Iterations: ROOT_MAX_ITERATIONS, Converged: false}
 }
 
-func appendRoot(roots, polynomial []float64, left, right float64) []float64 {
+func appendRoot(roots []RootResult, polynomial Polynomial, left, right float64) []RootResult {
 	if left == right {
 		return roots
 	}
 
-	leftValue, rightValue := value(polynomial, left), value(polynomial, right)
+	leftValue, rightValue := polynomial.Value(left), polynomial.Value(right)
 
 	if leftValue == 0 {
-		return append(roots, left)
+		return append(roots, RootResult{Value: left, Converged: true})
 	}
 
 	if rightValue == 0 {
-		return append(roots, right)
+		return append(roots, RootResult{Value: right, Converged: true})
 	}
 
 	if math.Signbit(leftValue) == math.Signbit(rightValue) {
 		return roots
 	}
 
-	middle := (left + right) / 2
+	return append(roots, safeguardedNewton(polynomial, polynomial.Derivative(), left, right))
+}
 
-	// do bisections until Fourier condition is true
-	for ;!checkFourier(polynomial, middle); {
-		left, right = bisection(polynomial, left, right)
-		middle = (left + right) / 2
-	}
+// the value is zero up to the rounding errors of the evaluation
+func isTangentRoot(polynomial Polynomial, point float64) bool {
+	return math.Abs(polynomial.Value(point)) <= TANGENT_THRESHOLD*evaluationBound(polynomial, point)
+}
 
-	differential := diff(polynomial)
-	prev := middle
-	cur := newton(polynomial, differential, prev)
-	for math.Abs(prev - cur) > NEWTON_ACCURACY {
-		prev = cur
-		cur = newton(polynomial, differential, prev)
-	}
+// returns distinct real roots in ascending order
+// with iteration counts and convergence flags
+func FindPolynomialRootsDetailed(polynomial Polynomial) []RootResult {
+	polynomial = polynomial.Trim()
 
-	return append(roots, cur)
-}
+	deg := len(polynomial) - 1
+	if deg < 1 {
+		return nil
+	}
 
-func FindPolynomialRoots(polynomial []float64) []float64 {
 	// linear polynomial
-	deg := len(polynomial) - 1
 	if deg == 1 {
-		return []float64{-polynomial[0] / polynomial[1]}
+		return []RootResult{{Value: -polynomial[0] / polynomial[1], Converged: true}}
 	}
 
-	var roots []float64
+	var roots []RootResult
 	leftBound, rightBound := boundRoots(polynomial)
-	extremes := FindPolynomialRoots(diff(polynomial))
+	extremes := FindPolynomialRoots(polynomial.Derivative())
+
+	// polynomial is monotone between extremes, so if an extreme is a (tangent) root,
+	// there are no other roots in neighbouring intervals
+	points := append(append([]float64{leftBound}, extremes...), rightBound)
+	tangent := make([]bool, len(points))
+	for i := 1; i+1 < len(points); i++ {
+		tangent[i] = isTangentRoot(polynomial, points[i])
+	}
+
+	for i := 0; i+1 < len(points); i++ {
+		if tangent[i] {
+			roots = append(roots, RootResult{Value: points[i], Converged: true})
+		}
+		if !tangent[i] && !tangent[i+1] {
+			roots = appendRoot(roots, polynomial, points[i], points[i+1])
+		}
+	}
+
+	return roots
+}
+
+// returns distinct real roots in ascending order
+func FindPolynomialRoots(polynomial Polynomial) []float64 {
+	var roots []float64
+	for _, root := range FindPolynomialRootsDetailed(polynomial) {
+		roots = append(roots, root.Value)
+	}
+	return roots
+}
 
-	if len(extremes) == 0 {
-		return appendRoot(roots, polynomial, leftBound, rightBound)
+// Yun's algorithm: polynomial = c * q_1 * q_2^2 * ... * q_k^k,
+// q_i are square-free and pairwise coprime, factors[i - 1] = q_i
+// rounding errors can break the factorization (d never vanishes), then the polynomial is considered square-free
+// constant and zero polynomials have no factors (and no roots to find)
+func SquareFreeFactorization(polynomial Polynomial) []Polynomial {
+	if polynomial.Degree() < 1 {
+		return nil
 	}
 
-	roots = appendRoot(roots, polynomial, leftBound, extremes[0])
-	for i := 1; i < len(extremes); i++ {
-		roots = appendRoot(roots, polynomial, extremes[i - 1], extremes[i])
+	derivative := polynomial.Derivative()
+	divisor := GCD(polynomial, derivative)
+
+	b := polynomial.Div(divisor)
+	d := derivative.Div(divisor).Sub(b.Derivative())
+
+	var factors []Polynomial
+	degree := 0
+	for b.Degree() > 0 && len(factors) < polynomial.Degree() {
+		factor := GCD(b, d)
+		factors = append(factors, factor)
+		degree += len(factors) * factor.Degree()
+
+		b = b.Div(factor)
+		d = d.Div(factor).Sub(b.Derivative())
+	}
+
+	if polynomial.Degree() > 0 && (b.Degree() > 0 || degree != polynomial.Degree()) {
+		return []Polynomial{polynomial}
+	}
+	return factors
+}
+
+type PolynomialRoot struct {
+	Value        float64
+	Multiplicity int
+}
+
+// distinct real roots with multiplicities in ascending order,
+// roots of every square-free factor are simple, so Newton method converges quadratically
+func FindPolynomialRootsWithMultiplicity(polynomial Polynomial) []PolynomialRoot {
+	var roots []PolynomialRoot
+	for i, factor := range SquareFreeFactorization(polynomial) {
+		for _, root := range FindPolynomialRoots(factor) {
+			roots = append(roots, PolynomialRoot{Value: root, Multiplicity: i + 1})
+		}
 	}
-	roots = appendRoot(roots, polynomial, extremes[len(extremes) - 1], rightBound)
 
+	sort.Slice(roots, func(i, j int) bool {
+		return roots[i].Value < roots[j].Value
+	})
 	return roots
 }
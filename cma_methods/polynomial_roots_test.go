@@ -0,0 +1,84 @@
+package cma_methods
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSquareFreeFactorization(t *testing.T) {
+	tests := []struct {
+		name       string
+		polynomial Polynomial
+		degrees    []int
+	}{
+		// (x - 1)^2 * (x - 2)
+		{"double root", Polynomial{-2, 5, -4, 1}, []int{1, 1}},
+		// (x - 1)^3 * (x + 2)
+		{"triple root", Polynomial{-1, 3, -3, 1}.Mul(Polynomial{2, 1}), []int{1, 0, 1}},
+		{"square-free", Polynomial{1, 0, 1}, []int{2}},
+		{"constant", Polynomial{3}, []int{}},
+		{"zero", Polynomial{0}, []int{}},
+		{"empty", Polynomial{}, []int{}},
+		// characteristic polynomial of a random 20x20 matrix, rounding errors break the factorization
+		{"large coefficients", Polynomial{
+			-2.1441898198242992e+23, -1.1380715350755985e+23, -3.601303157886683e+22, -5.265234468530199e+19,
+			3.290947091875524e+20, 2.8830555651964723e+19, -5.711450933417796e+17, -1.0341245027624774e+17,
+			-8.722123883146875e+14, 6.877212780475664e+12, 7.019936936656081e+12, 2.6768206178342126e+11,
+			2.21123703460894e+10, -1.4312133578094559e+09, -1.2799867799627694e+08, -315344.485571355,
+			183519.45215600793, -9956.603118373932, -236.90210541255124, 29.252480778858292, 1,
+		}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			factors := SquareFreeFactorization(test.polynomial)
+			degree := 0
+			for i, factor := range factors {
+				degree += (i + 1) * factor.Degree()
+			}
+			if test.polynomial.Degree() > 0 && degree != test.polynomial.Degree() {
+				t.Errorf("factors %v don't add up to degree %v", factors, test.polynomial.Degree())
+			}
+			if test.degrees == nil {
+				return
+			}
+
+			if len(factors) != len(test.degrees) {
+				t.Fatalf("got %v factors %v, expected %v", len(factors), factors, len(test.degrees))
+			}
+			for i, factor := range factors {
+				if factor.Degree() != test.degrees[i] {
+					t.Errorf("factor %v is %v, expected degree %v", i+1, factor, test.degrees[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFindPolynomialRootsWithMultiplicity(t *testing.T) {
+	tests := []struct {
+		name       string
+		polynomial Polynomial
+		roots      []PolynomialRoot
+	}{
+		// (x - 1)^2 * (x - 2)
+		{"double root", Polynomial{-2, 5, -4, 1}, []PolynomialRoot{{1, 2}, {2, 1}}},
+		{"complex roots", Polynomial{1, 0, 1}, nil},
+		{"constant", Polynomial{3}, nil},
+		{"zero", Polynomial{0}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			roots := FindPolynomialRootsWithMultiplicity(test.polynomial)
+			if len(roots) != len(test.roots) {
+				t.Fatalf("got roots %v, expected %v", roots, test.roots)
+			}
+			for i, root := range roots {
+				if root.Multiplicity != test.roots[i].Multiplicity || math.Abs(root.Value-test.roots[i].Value) > 1e-9 {
+					t.Errorf("got roots %v, expected %v", roots, test.roots)
+				}
+			}
+		})
+	}
+}
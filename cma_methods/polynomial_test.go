@@ -0,0 +1,47 @@
+package cma_methods
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPolynomialMul(t *testing.T) {
+	tests := []struct {
+		name     string
+		lhs, rhs Polynomial
+		product  Polynomial
+	}{
+		{"(x - 1)(x + 1)", Polynomial{-1, 1}, Polynomial{1, 1}, Polynomial{-1, 0, 1}},
+		{"small leading coefficients are kept", Polynomial{0, 1e-6}, Polynomial{0, 1e-6}, Polynomial{0, 0, 1e-12}},
+		{"zero", Polynomial{0}, Polynomial{1, 2}, Polynomial{0}},
+		{"constant", Polynomial{2}, Polynomial{1, 2}, Polynomial{2, 4}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if product := test.lhs.Mul(test.rhs); !reflect.DeepEqual(product, test.product) {
+				t.Errorf("%v * %v = %v, expected %v", test.lhs, test.rhs, product, test.product)
+			}
+		})
+	}
+}
+
+func TestPolynomialTrimLeading(t *testing.T) {
+	tests := []struct {
+		name       string
+		polynomial Polynomial
+		trimmed    Polynomial
+	}{
+		{"rounding error", Polynomial{2, -3, 1, 1e-15}, Polynomial{2, -3, 1}},
+		{"small scale", Polynomial{2e-20, -3e-20, 1e-20}, Polynomial{2e-20, -3e-20, 1e-20}},
+		{"zero", Polynomial{0, 0}, Polynomial{0}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if trimmed := test.polynomial.TrimLeading(LEADING_THRESHOLD); !reflect.DeepEqual(trimmed, test.trimmed) {
+				t.Errorf("%v is trimmed to %v, expected %v", test.polynomial, trimmed, test.trimmed)
+			}
+		})
+	}
+}
@@ -17,6 +17,20 @@ const (
 	STUCK_CASE
 )
 
+func (eigenvalueCase EigenvalueCase) String() string {
+	switch eigenvalueCase {
+	case REAL_EIGENVALUE_CASE:
+		return "real"
+	case OPPOSITE_PAIRED_EIGENVALUES_CASE:
+		return "opposite"
+	case COMPLEX_EIGENVALUES_CASE:
+		return "complex"
+	case STUCK_CASE:
+		return "stuck"
+	}
+	return "unknown"
+}
+
 const STOP_THRESHOLD = 1e-11
 
 type Eigenvector struct {
@@ -155,6 +169,11 @@ func makeComplexEigenvector(start, last, prev, cur []float64) ([]*Eigenvector, b
 }
 
 func FindMaxEigenvalues(squareMatrix *matrix.SquareMatrix, initApprox *matrix.Column) ([]*Eigenvector, EigenvalueCase, int) {
+	return FindMaxEigenvaluesWithLimit(squareMatrix, initApprox, 0)
+}
+
+// STUCK_CASE, if none of the cases is detected in maxIterations (0 means no limit)
+func FindMaxEigenvaluesWithLimit(squareMatrix *matrix.SquareMatrix, initApprox *matrix.Column, maxIterations int) ([]*Eigenvector, EigenvalueCase, int) {
 	// middle = iteration before prev, last = iteration before middle
 
 	start, _ := matrix.MultiplyMatrixOnColumn(squareMatrix, initApprox)
@@ -163,7 +182,7 @@ func FindMaxEigenvalues(squareMatrix *matrix.SquareMatrix, initApprox *matrix.Co
 	// new modification : process a bunch of 4 vectors
 	// to simplify evaluations
 	iterations := 0
-	for {
+	for maxIterations == 0 || iterations < maxIterations {
 		iterations += 3
 		// process 4 iterations at once
 		last, _ = matrix.MultiplyMatrixOnColumn(squareMatrix, start)
@@ -196,4 +215,5 @@ func FindMaxEigenvalues(squareMatrix *matrix.SquareMatrix, initApprox *matrix.Co
 		utils.NormColumn(start)
 	}
 
+	return nil, STUCK_CASE, iterations
 }
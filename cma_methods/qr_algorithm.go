@@ -3,6 +3,8 @@ package cma_methods
 import (
 	"cma-lab-go/matrix"
 	"cma-lab-go/utils"
+	"errors"
+	"fmt"
 	"math"
 	"math/cmplx"
 )
@@ -60,55 +62,142 @@ func directZeroElement(transform, squareMatrix *matrix.SquareMatrix, i, j int) {
 	rotateRight(transform, j, i, cos, sin)
 }
 
-func doQRIteration(transform, squareMatrix *matrix.SquareMatrix) {
-	size := len(squareMatrix.Data)
+// similarity transformation with the rotation of rows (columns) i < j, which zeroes b against a
+func rotateSimilar(transform, squareMatrix *matrix.SquareMatrix, i, j int, a, b float64) {
+	denom := math.Hypot(a, b)
+	if denom == 0 {
+		return
+	}
+	cos, sin := a/denom, -b/denom
 
-	// this is crutch for degenerate rotations
-	// postponedRightRotationsValues / postponedRightRotationsIndices
-	var postponedRRV [][]float64
-	var postponedRRI [][]int
-	for i := 1; i < size; i++ {
-		j := i - 1
+	rotateLeft(squareMatrix, i, j, cos, sin)
+	rotateRight(squareMatrix, i, j, cos, -sin) // this is for preserving Q * A * Q^T
+	rotateRight(transform, i, j, cos, -sin)
+}
+
+// subdiagonal elements below the rounding errors of their diagonal neighbours are made zero
+// (they may be above the absolute threshold for large elements, but never decrease further)
+func deflateSubdiagonal(squareMatrix *matrix.SquareMatrix) {
+	epsilon := math.Nextafter(1, 2) - 1
+	for i := 1; i < len(squareMatrix.Data); i++ {
+		neighbours := math.Abs(squareMatrix.Data[i-1][i-1]) + math.Abs(squareMatrix.Data[i][i])
+		if math.Abs(squareMatrix.Data[i][i-1]) <= epsilon*neighbours {
+			squareMatrix.Data[i][i-1] = 0
+		}
+	}
+}
+
+// 2x2 block [j, j + 1] has complex eigenvalues (the discriminant has the scale of the block squared,
+// so the threshold is multiplied by the block norm)
+func isComplexBlock(squareMatrix *matrix.SquareMatrix, j int, threshold float64) bool {
+	a, b := squareMatrix.Data[j][j], squareMatrix.Data[j][j+1]
+	c, d := squareMatrix.Data[j+1][j], squareMatrix.Data[j+1][j+1]
+	scale := math.Abs(a) + math.Abs(b) + math.Abs(c) + math.Abs(d)
+	return (a+d)*(a+d)-4*(a*d-b*c) < threshold*scale
+}
 
-		if math.Abs(squareMatrix.Data[i][j]) < ZERO_THRESHOLD {
+// the lowest unreduced block [low, high] of the Hessenberg matrix, which isn't converged yet
+// (high == 0 if the matrix is converged, see stopCheck)
+func activeBlock(squareMatrix *matrix.SquareMatrix, threshold float64) (int, int) {
+	high := len(squareMatrix.Data) - 1
+	for high > 0 {
+		if math.Abs(squareMatrix.Data[high][high-1]) <= threshold {
+			high--
+			continue
+		}
+		if (high == 1 || math.Abs(squareMatrix.Data[high-1][high-2]) <= threshold) &&
+			isComplexBlock(squareMatrix, high-1, threshold) {
+			high -= 2
 			continue
 		}
+		break
+	}
+	if high <= 0 {
+		return 0, 0
+	}
+
+	low := high - 1
+	for low > 0 && math.Abs(squareMatrix.Data[low][low-1]) > threshold {
+		low--
+	}
+	return low, high
+}
 
-		denom := math.Sqrt(squareMatrix.Data[j][j] * squareMatrix.Data[j][j] +
-			squareMatrix.Data[i][j] * squareMatrix.Data[i][j])
-		cos, sin := squareMatrix.Data[j][j]/denom, -squareMatrix.Data[i][j]/denom
+// Francis double shift step on the block [low, high] with the shifts, which are the roots of
+// lambda^2 - trace * lambda + det (eigenvalues of the trailing 2x2 block or the exceptional ones):
+// the bulge made by the first column of (H - l1 * I)(H - l2 * I) is chased down by rotations
+func doQRIteration(transform, squareMatrix *matrix.SquareMatrix, low, high int, exceptional bool) {
+	h := squareMatrix.Data
+	if low > 0 {
+		// deflated, rotations of the block mustn't spread it below the subdiagonal
+		h[low][low-1] = 0
+	}
 
-		rotateLeft(squareMatrix, j, i, cos, sin)
+	a, b, c, d := h[high-1][high-1], h[high-1][high], h[high][high-1], h[high][high]
+	trace, det := a+d, a*d-b*c
+	if exceptional && high-low > 1 {
+		// shifts of LAPACK dlahqr for stagnated blocks
+		s := math.Abs(c)
+		if high-2 >= low {
+			s += math.Abs(h[high-1][high-2])
+		}
+		trace, det = 2*(0.75*s+d), (0.75*s+d)*(0.75*s+d)+0.4375*s*s
+	}
 
-		postponedRRV = append(postponedRRV, []float64{cos, -sin})
-		postponedRRI = append(postponedRRI, []int{j, i})
+	if high-low == 1 {
+		// 2x2 block with real eigenvalues, one step with the eigenvalue closer to d as the shift
+		// splits it (a double shift step would be degenerate)
+		root := math.Sqrt(math.Max(trace*trace-4*det, 0))
+		shift := (trace + root) / 2
+		if math.Abs(shift-d) > math.Abs((trace-root)/2-d) {
+			shift = (trace - root) / 2
+		}
+		rotateSimilar(transform, squareMatrix, low, high, h[low][low]-shift, h[high][low])
+		h[high][low] = 0
+		return
 	}
 
-	for i, _ := range postponedRRI {
-		rotateRight(squareMatrix, postponedRRI[i][0], postponedRRI[i][1],
-			postponedRRV[i][0], postponedRRV[i][1]) // this is for preserving Q * A * Q^T
-		rotateRight(transform, postponedRRI[i][0], postponedRRI[i][1],
-			postponedRRV[i][0], postponedRRV[i][1])
+	// first column of (H - l1 * I)(H - l2 * I)
+	x := h[low][low]*h[low][low] + h[low][low+1]*h[low+1][low] - trace*h[low][low] + det
+	y := h[low+1][low] * (h[low][low] + h[low+1][low+1] - trace)
+	z := h[low+1][low] * h[low+2][low+1]
+
+	for k := low; k < high; k++ {
+		if k > low {
+			x, y, z = h[k][k-1], h[k+1][k-1], 0
+			if k+2 <= high {
+				z = h[k+2][k-1]
+			}
+		}
+
+		if k+2 <= high {
+			rotateSimilar(transform, squareMatrix, k+1, k+2, y, z)
+			y = math.Hypot(y, z)
+		}
+		rotateSimilar(transform, squareMatrix, k, k+1, x, y)
+
+		if k > low {
+			h[k+1][k-1] = 0
+			if k+2 <= high {
+				h[k+2][k-1] = 0
+			}
+		}
 	}
 }
 
-func stopCheck(squareMatrix *matrix.SquareMatrix) bool {
+func stopCheck(squareMatrix *matrix.SquareMatrix, threshold float64) bool {
 	size := len(squareMatrix.Data)
 
 	for i := 1; i < size; i++ {
 		j := i - 1
-		if math.Abs(squareMatrix.Data[i][j]) > ZERO_THRESHOLD {
+		if math.Abs(squareMatrix.Data[i][j]) > threshold {
 			// block
-			trace := squareMatrix.Data[j][j] + squareMatrix.Data[j+1][j+1]
-			det := squareMatrix.Data[j+1][j+1]*squareMatrix.Data[j][j] -
-				squareMatrix.Data[i][j]*squareMatrix.Data[j][i]
-			// this is real
-			if trace*trace-4*det >= ZERO_THRESHOLD {
+			if !isComplexBlock(squareMatrix, j, threshold) {
 				// real block
 				return false
 			} else {
 				// complex block
-				if i + 1 < size && j + 1 < size && math.Abs(squareMatrix.Data[i + 1][j + 1]) > ZERO_THRESHOLD {
+				if i + 1 < size && j + 1 < size && math.Abs(squareMatrix.Data[i + 1][j + 1]) > threshold {
 					return false
 				}
 			}
@@ -118,13 +207,13 @@ func stopCheck(squareMatrix *matrix.SquareMatrix) bool {
 	return true
 }
 
-func extractEigenvalues(squareMatrix *matrix.SquareMatrix) []complex128 {
+func extractEigenvalues(squareMatrix *matrix.SquareMatrix, threshold float64) []complex128 {
 	size := len(squareMatrix.Data)
 
 	var eigenvalues []complex128
 	for i := 1; i < size; i++ {
 		j := i - 1
-		if math.Abs(squareMatrix.Data[i][j]) > ZERO_THRESHOLD {
+		if math.Abs(squareMatrix.Data[i][j]) > threshold {
 			// block
 			trace := squareMatrix.Data[j][j] + squareMatrix.Data[j+1][j+1]
 			det := squareMatrix.Data[j+1][j+1]*squareMatrix.Data[j][j] -
@@ -144,7 +233,7 @@ func extractEigenvalues(squareMatrix *matrix.SquareMatrix) []complex128 {
 	// extra check for the real matrix[size - 1][size - 1] element
 	// [complex case is processed in cycle]
 
-	if math.Abs(squareMatrix.Data[size-1][size-2]) < ZERO_THRESHOLD {
+	if size == 1 || math.Abs(squareMatrix.Data[size-1][size-2]) <= threshold {
 		eigenvalues = append(eigenvalues, complex(squareMatrix.Data[size-1][size-1], 0))
 	}
 
@@ -152,13 +241,13 @@ func extractEigenvalues(squareMatrix *matrix.SquareMatrix) []complex128 {
 }
 
 // fills with zero complex numbers
-func extractEigenvectors(squareMatrix, transform *matrix.SquareMatrix) [][]float64 {
+func extractEigenvectors(squareMatrix, transform *matrix.SquareMatrix, threshold float64) [][]float64 {
 	size := len(squareMatrix.Data)
 
 	eigenvectors := make([][]float64, 0, size)
 	for i := 1; i < size; i++ {
 		j := i - 1
-		if math.Abs(squareMatrix.Data[i][j]) > ZERO_THRESHOLD {
+		if math.Abs(squareMatrix.Data[i][j]) > threshold {
 			eigenvectors = append(eigenvectors, nil, nil)
 			i++
 		} else {
@@ -170,7 +259,7 @@ func extractEigenvectors(squareMatrix, transform *matrix.SquareMatrix) [][]float
 		}
 	}
 
-	if math.Abs(squareMatrix.Data[size-1][size-2]) < ZERO_THRESHOLD {
+	if size == 1 || math.Abs(squareMatrix.Data[size-1][size-2]) <= threshold {
 		vector := make([]float64, 0, size)
 		for k := 0; k < size; k++ {
 			vector = append(vector, transform.Data[k][size-1])
@@ -180,9 +269,9 @@ func extractEigenvectors(squareMatrix, transform *matrix.SquareMatrix) [][]float
 	return eigenvectors
 }
 
-// returns a slice of eigenvalues and slice of eigenvector
-// if eigenvalues is complex (Re != 0) => two vectors at this index == nil
-func SolveQR(squareMatrixOriginal *matrix.SquareMatrix) ([]complex128, [][]float64, int) {
+// A = Q * H * Q^T, H is upper Hessenberg
+// returns H and Q
+func reduceToHessenberg(squareMatrixOriginal *matrix.SquareMatrix) (*matrix.SquareMatrix, *matrix.SquareMatrix) {
 	size := len(squareMatrixOriginal.Data)
 
 	// deep matrix copy
@@ -194,22 +283,106 @@ func SolveQR(squareMatrixOriginal *matrix.SquareMatrix) ([]complex128, [][]float
 	}
 
 	// hessenberg
-	// direct rotations
+	// direct rotations, every nonzero element is reduced (small ones are significant for small matrices)
 	transform := utils.MakeIdentity(size)
 	for j := 0; j < size - 2; j++ {
 		for i := j + 2; i < size; i++ {
-			if math.Abs(squareMatrix.Data[i][j]) > ZERO_THRESHOLD {
+			if squareMatrix.Data[i][j] != 0 {
 				directZeroElement(transform, &squareMatrix, i, j)
 			}
 		}
 	}
 
-	// iterations of QR using rotations (non-direct)
-	iterations := 0
-	for ;!stopCheck(&squareMatrix); {
-		doQRIteration(transform, &squareMatrix)
+	return &squareMatrix, transform
+}
+
+// QR-algorithm settings: subdiagonal elements below Tolerance * ||A||_F are considered zero,
+// MaxIterations = 0 means the default limit of QR_ITERATIONS_PER_EIGENVALUE per eigenvalue
+type QROptions struct {
+	Tolerance     float64
+	MaxIterations int
+}
+
+// iterations limit like in LAPACK: 30 per eigenvalue, but at least 300
+const (
+	QR_ITERATIONS_PER_EIGENVALUE = 30
+	QR_MIN_ITERATIONS_LIMIT      = 300
+)
+
+// blocks, which aren't split for this number of iterations, get an exceptional shift
+const QR_EXCEPTIONAL_SHIFT_PERIOD = 10
+
+// wrapped by the errors of the QR-algorithm, which exceeded the iterations limit
+var ErrQRNotConverged = errors.New("qr-algorithm didn't converge")
+
+var DefaultQROptions = QROptions{Tolerance: ZERO_THRESHOLD}
+
+// absolute threshold of the subdiagonal elements for the matrix
+func (options QROptions) threshold(squareMatrix *matrix.SquareMatrix) float64 {
+	return options.Tolerance * utils.FrobeniusNorm(squareMatrix)
+}
+
+func (options QROptions) iterationsLimit(size int) int {
+	if options.MaxIterations > 0 {
+		return options.MaxIterations
+	}
+	limit := QR_ITERATIONS_PER_EIGENVALUE * size
+	if limit < QR_MIN_ITERATIONS_LIMIT {
+		limit = QR_MIN_ITERATIONS_LIMIT
+	}
+	return limit
+}
+
+// real Schur form: A = Q * T * Q^T, T is quasi upper triangular
+// (2x2 diagonal blocks correspond to complex eigenvalues)
+// returns T, Q and number of QR iterations,
+// error (ErrQRNotConverged), if they exceed the default limit, T isn't converged then
+func SchurDecomposition(squareMatrixOriginal *matrix.SquareMatrix) (*matrix.SquareMatrix, *matrix.SquareMatrix, int, error) {
+	return SchurDecompositionWithOptions(squareMatrixOriginal, DefaultQROptions)
+}
+
+// error (ErrQRNotConverged), if QR iterations didn't converge in the options limit
+func SchurDecompositionWithOptions(squareMatrixOriginal *matrix.SquareMatrix, options QROptions) (*matrix.SquareMatrix, *matrix.SquareMatrix, int, error) {
+	squareMatrix, transform := reduceToHessenberg(squareMatrixOriginal)
+	threshold := options.threshold(squareMatrixOriginal)
+
+	// shifted QR iterations on the lowest unreduced block
+	limit := options.iterationsLimit(len(squareMatrix.Data))
+	iterations, stagnation, lastHigh := 0, 0, -1
+	for deflateSubdiagonal(squareMatrix); !stopCheck(squareMatrix, threshold); deflateSubdiagonal(squareMatrix) {
+		if iterations >= limit {
+			return squareMatrix, transform, iterations,
+				fmt.Errorf("%w in %v iterations", ErrQRNotConverged, limit)
+		}
+
+		low, high := activeBlock(squareMatrix, threshold)
+		if high == 0 {
+			break
+		}
+		if high != lastHigh {
+			stagnation, lastHigh = 0, high
+		}
+		stagnation++
+		doQRIteration(transform, squareMatrix, low, high, stagnation%QR_EXCEPTIONAL_SHIFT_PERIOD == 0)
 		iterations++
 	}
 
-	return extractEigenvalues(&squareMatrix), extractEigenvectors(&squareMatrix, transform), iterations
+	return squareMatrix, transform, iterations, nil
+}
+
+// returns a slice of eigenvalues and slice of eigenvector
+// if eigenvalues is complex (Re != 0) => two vectors at this index == nil
+// error (ErrQRNotConverged) and nil slices, if QR iterations exceed the default limit
+func SolveQR(squareMatrixOriginal *matrix.SquareMatrix) ([]complex128, [][]float64, int, error) {
+	return SolveQRWithOptions(squareMatrixOriginal, DefaultQROptions)
+}
+
+func SolveQRWithOptions(squareMatrixOriginal *matrix.SquareMatrix, options QROptions) ([]complex128, [][]float64, int, error) {
+	squareMatrix, transform, iterations, err := SchurDecompositionWithOptions(squareMatrixOriginal, options)
+	if err != nil {
+		return nil, nil, iterations, err
+	}
+	threshold := options.threshold(squareMatrixOriginal)
+	return extractEigenvalues(squareMatrix, threshold),
+		extractEigenvectors(squareMatrix, transform, threshold), iterations, nil
 }
@@ -0,0 +1,138 @@
+package cma_methods
+
+import (
+	"cma-lab-go/matrix"
+	"errors"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// every expected value is matched with the nearest unmatched actual one
+// (sorting can't pair values, which real parts differ by the rounding errors)
+func checkComplexValues(t *testing.T, actual, expected []complex128, accuracy float64) {
+	t.Helper()
+	if len(actual) != len(expected) {
+		t.Fatalf("got %v values %v, expected %v", len(actual), actual, expected)
+	}
+	// relative to the largest value, small matrices have small spectra
+	scale := 0.0
+	for _, value := range expected {
+		scale = math.Max(scale, cmplx.Abs(value))
+	}
+	if scale == 0 {
+		scale = 1
+	}
+
+	matched := make([]bool, len(actual))
+	for _, value := range expected {
+		nearest := -1
+		for i := range actual {
+			if !matched[i] && (nearest == -1 || cmplx.Abs(actual[i]-value) < cmplx.Abs(actual[nearest]-value)) {
+				nearest = i
+			}
+		}
+		matched[nearest] = true
+		if cmplx.Abs(actual[nearest]-value) > accuracy*scale {
+			t.Errorf("got %v, expected %v", actual, expected)
+			return
+		}
+	}
+}
+
+func cyclicPermutation(size int) [][]float64 {
+	data := make([][]float64, size)
+	for i := range data {
+		data[i] = make([]float64, size)
+		data[i][(i+1)%size] = 1
+	}
+	return data
+}
+
+func TestSolveQR(t *testing.T) {
+	sqrt3 := math.Sqrt(3) / 2
+	tests := []struct {
+		name        string
+		data        [][]float64
+		eigenvalues []complex128
+	}{
+		{"1x1", [][]float64{{5}}, []complex128{5}},
+		{"symmetric permutation", [][]float64{{0, 1}, {1, 0}}, []complex128{-1, 1}},
+		{"cyclic permutation", cyclicPermutation(3), []complex128{1, complex(-0.5, sqrt3), complex(-0.5, -sqrt3)}},
+		{"cyclic permutation 4x4", cyclicPermutation(4), []complex128{1, -1, 1i, -1i}},
+		{"rotation", [][]float64{{0, -1}, {1, 0}}, []complex128{1i, -1i}},
+		{"zero", [][]float64{{0, 0}, {0, 0}}, []complex128{0, 0}},
+		{"sampleA", [][]float64{{-24, 0, 25}, {25, 1, -25}, {-50, 0, 51}}, []complex128{26, 1, 1}},
+		{"sampleA * 1e-11", [][]float64{{-24e-11, 0, 25e-11}, {25e-11, 1e-11, -25e-11}, {-50e-11, 0, 51e-11}},
+			[]complex128{26e-11, 1e-11, 1e-11}},
+		{"rotation * 1e-12", [][]float64{{0, -1e-12}, {1e-12, 0}}, []complex128{1e-12i, -1e-12i}},
+		{"symmetric permutation * 1e-20", [][]float64{{0, 1e-20}, {1e-20, 0}}, []complex128{-1e-20, 1e-20}},
+		{"large elements", [][]float64{{1e9, 2e9, 0}, {-2e9, 1e9, 3e9}, {0, 1e9, -5e8}}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			squareMatrix, err := matrix.NewSquareMatrix(test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			eigenvalues, eigenvectors, _, err := SolveQRWithOptions(squareMatrix, DefaultQROptions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(eigenvectors) != len(test.data) {
+				t.Errorf("got %v eigenvectors, expected %v", len(eigenvectors), len(test.data))
+			}
+
+			if test.eigenvalues != nil {
+				checkComplexValues(t, eigenvalues, test.eigenvalues, 1e-9)
+				return
+			}
+			// only the trace is known
+			var sum complex128 = 0
+			trace := 0.0
+			for i := range test.data {
+				sum += eigenvalues[i]
+				trace += test.data[i][i]
+			}
+			if cmplx.Abs(sum-complex(trace, 0)) > 1e-6 {
+				t.Errorf("sum of eigenvalues %v, trace %v", sum, trace)
+			}
+		})
+	}
+}
+
+func TestSolveQRIterationsLimit(t *testing.T) {
+	squareMatrix, _ := matrix.NewSquareMatrix(cyclicPermutation(6))
+	eigenvalues, _, iterations, err := SolveQRWithOptions(squareMatrix, QROptions{Tolerance: ZERO_THRESHOLD, MaxIterations: 2})
+	if !errors.Is(err, ErrQRNotConverged) {
+		t.Errorf("got error %v after %v iterations, expected ErrQRNotConverged", err, iterations)
+	}
+	if eigenvalues != nil {
+		t.Errorf("got eigenvalues %v of the unconverged matrix", eigenvalues)
+	}
+}
+
+func TestFindPolynomialRootsCompanion(t *testing.T) {
+	tests := []struct {
+		name       string
+		polynomial Polynomial
+		roots      []complex128
+	}{
+		{"x^2 - 1", Polynomial{-1, 0, 1}, []complex128{-1, 1}},
+		{"x^2 + 1", Polynomial{1, 0, 1}, []complex128{1i, -1i}},
+		{"(x^2 - 1)(x^2 - 4)", Polynomial{4, 0, -5, 0, 1}, []complex128{-2, -1, 1, 2}},
+		{"(x - 1)^3 (x + 1)", Polynomial{-1, 2, 0, -2, 1}, []complex128{-1, 1, 1, 1}},
+		{"x^4 - 1", Polynomial{-1, 0, 0, 0, 1}, []complex128{-1, 1, 1i, -1i}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			roots, _, err := FindPolynomialRootsCompanion(test.polynomial)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkComplexValues(t, roots, test.roots, 1e-12)
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package cma_methods
+
+import (
+	"cma-lab-go/matrix"
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+const QZ_MAX_ITERATIONS_PER_EIGENVALUE = 30
+
+// eigenvalue of the pencil (A, B) is Alpha / Beta
+// if Beta == 0, then the eigenvalue is infinite
+type GeneralizedEigenvalue struct {
+	Alpha    complex128
+	Beta     complex128
+	Infinite bool
+}
+
+func (eigenvalue *GeneralizedEigenvalue) Value() complex128 {
+	if eigenvalue.Infinite {
+		return cmplx.Inf()
+	}
+	return eigenvalue.Alpha / eigenvalue.Beta
+}
+
+func toComplexMatrix(squareMatrix *matrix.SquareMatrix) [][]complex128 {
+	size := len(squareMatrix.Data)
+	result := make([][]complex128, size)
+	for i := 0; i < size; i++ {
+		result[i] = make([]complex128, size)
+		for j := 0; j < size; j++ {
+			result[i][j] = complex(squareMatrix.Data[i][j], 0)
+		}
+	}
+	return result
+}
+
+// complex Givens rotation
+// [ c       s ] [f]   [r]
+// [ -conj(s) c ] [g] = [0]
+func complexGivens(f, g complex128) (float64, complex128) {
+	if g == 0 {
+		return 1, 0
+	}
+	if f == 0 {
+		return 0, 1
+	}
+
+	absF := cmplx.Abs(f)
+	norm := math.Hypot(absF, cmplx.Abs(g))
+	return absF / norm, (f / complex(absF, 0)) * cmplx.Conj(g) / complex(norm, 0)
+}
+
+// applies rotation to rows p and q, starting from column from
+func complexRotateRows(m [][]complex128, p, q, from int, c float64, s complex128) {
+	for j := from; j < len(m); j++ {
+		u, v := m[p][j], m[q][j]
+		m[p][j] = complex(c, 0)*u + s*v
+		m[q][j] = -cmplx.Conj(s)*u + complex(c, 0)*v
+	}
+}
+
+// applies rotation to columns p and q, rows [0, to]
+func complexRotateColumns(m [][]complex128, p, q, to int, c float64, s complex128) {
+	for i := 0; i <= to; i++ {
+		u, v := m[i][p], m[i][q]
+		m[i][p] = complex(c, 0)*u - cmplx.Conj(s)*v
+		m[i][q] = s*u + complex(c, 0)*v
+	}
+}
+
+// left rotation of rows p, q which zeroes target[q][column]
+// the same rotation is applied to other
+func zeroByRows(target, other [][]complex128, p, q, column int) {
+	c, s := complexGivens(target[p][column], target[q][column])
+	complexRotateRows(target, p, q, 0, c, s)
+	complexRotateRows(other, p, q, 0, c, s)
+	target[q][column] = 0
+}
+
+// right rotation of columns p, q (p < q) which zeroes target[row][p]
+// the same rotation is applied to other
+func zeroByColumns(target, other [][]complex128, p, q, row int) {
+	c, s := complexGivens(target[row][q], target[row][p])
+	size := len(target)
+	complexRotateColumns(target, p, q, size-1, c, s)
+	complexRotateColumns(other, p, q, size-1, c, s)
+	target[row][p] = 0
+}
+
+func frobeniusNorm(m [][]complex128) float64 {
+	var sum float64 = 0
+	for i := range m {
+		for j := range m[i] {
+			abs := cmplx.Abs(m[i][j])
+			sum += abs * abs
+		}
+	}
+	return math.Sqrt(sum)
+}
+
+// reduces A to upper Hessenberg and B to upper triangular form
+func hessenbergTriangular(lhs, rhs [][]complex128) {
+	size := len(lhs)
+
+	// B = Q * R
+	for j := 0; j < size-1; j++ {
+		for i := size - 1; i > j; i-- {
+			if rhs[i][j] != 0 {
+				zeroByRows(rhs, lhs, i-1, i, j)
+			}
+		}
+	}
+
+	// A to Hessenberg, the fill-in of B is removed by columns rotations
+	for j := 0; j < size-2; j++ {
+		for i := size - 1; i > j+1; i-- {
+			if lhs[i][j] == 0 {
+				continue
+			}
+			zeroByRows(lhs, rhs, i-1, i, j)
+			if rhs[i][i-1] != 0 {
+				zeroByColumns(rhs, lhs, i-1, i, i)
+			}
+		}
+	}
+}
+
+// eigenvalue of trailing 2x2 pencil, which is closer to A[hi][hi] / B[hi][hi]
+func qzShift(lhs, rhs [][]complex128, hi int) complex128 {
+	a11, a12, a21, a22 := lhs[hi-1][hi-1], lhs[hi-1][hi], lhs[hi][hi-1], lhs[hi][hi]
+	b11, b12, b22 := rhs[hi-1][hi-1], rhs[hi-1][hi], rhs[hi][hi]
+
+	// det(A - l * B) = a * l^2 - b * l + c
+	a := b11 * b22
+	b := a11*b22 + a22*b11 - a21*b12
+	c := a11*a22 - a12*a21
+
+	d := cmplx.Sqrt(b*b - 4*a*c)
+	first, second := (b+d)/(2*a), (b-d)/(2*a)
+
+	target := a22 / b22
+	if cmplx.Abs(first-target) < cmplx.Abs(second-target) {
+		return first
+	}
+	return second
+}
+
+// single shift QZ step on the active block [lo, hi]
+func doQZIteration(lhs, rhs [][]complex128, lo, hi int, shift complex128) {
+	c, s := complexGivens(lhs[lo][lo]-shift*rhs[lo][lo], lhs[lo+1][lo])
+	complexRotateRows(lhs, lo, lo+1, 0, c, s)
+	complexRotateRows(rhs, lo, lo+1, 0, c, s)
+
+	// chase the bulge
+	for k := lo; k < hi; k++ {
+		zeroByColumns(rhs, lhs, k, k+1, k+1)
+		if k+2 <= hi {
+			zeroByRows(lhs, rhs, k+1, k+2, k)
+		}
+	}
+}
+
+// B[j][j] == 0 : moves zero to B[hi][hi] and deflates infinite eigenvalue
+func deflateInfinite(lhs, rhs [][]complex128, lo, j, hi int) {
+	for k := j; k < hi; k++ {
+		c, s := complexGivens(rhs[k][k+1], rhs[k+1][k+1])
+		complexRotateRows(rhs, k, k+1, 0, c, s)
+		complexRotateRows(lhs, k, k+1, 0, c, s)
+		rhs[k+1][k+1] = 0
+
+		if k > lo {
+			zeroByColumns(lhs, rhs, k-1, k, k+1)
+		}
+	}
+
+	zeroByColumns(lhs, rhs, hi-1, hi, hi)
+}
+
+// QZ algorithm for the general problem A * x = lambda * B * x
+// returns generalized eigenvalues and number of iterations
+func SolveQZ(lhsOriginal, rhsOriginal *matrix.SquareMatrix) ([]*GeneralizedEigenvalue, int, error) {
+	size := len(lhsOriginal.Data)
+	if size != len(rhsOriginal.Data) {
+		return nil, 0, fmt.Errorf("inconsistent matrices sizes")
+	}
+
+	lhs, rhs := toComplexMatrix(lhsOriginal), toComplexMatrix(rhsOriginal)
+	lhsNorm, rhsNorm := frobeniusNorm(lhs), frobeniusNorm(rhs)
+	epsilon := math.Nextafter(1, 2) - 1
+
+	hessenbergTriangular(lhs, rhs)
+
+	iterations := 0
+	stuckCount := 0
+	hi := size - 1
+	for hi > 0 {
+		if stuckCount > QZ_MAX_ITERATIONS_PER_EIGENVALUE*size {
+			return nil, iterations, fmt.Errorf("QZ algorithm didn't converge")
+		}
+
+		// find the active block [lo, hi]
+		lo := hi
+		for ; lo > 0; lo-- {
+			scale := cmplx.Abs(lhs[lo][lo]) + cmplx.Abs(lhs[lo-1][lo-1])
+			if scale == 0 {
+				scale = lhsNorm
+			}
+			if cmplx.Abs(lhs[lo][lo-1]) <= epsilon*scale {
+				lhs[lo][lo-1] = 0
+				break
+			}
+		}
+
+		if lo == hi {
+			hi--
+			stuckCount = 0
+			continue
+		}
+
+		// infinite eigenvalues
+		infinite := -1
+		for j := lo; j <= hi; j++ {
+			if cmplx.Abs(rhs[j][j]) <= epsilon*rhsNorm {
+				rhs[j][j] = 0
+				infinite = j
+				break
+			}
+		}
+
+		if infinite != -1 {
+			deflateInfinite(lhs, rhs, lo, infinite, hi)
+			hi--
+			stuckCount = 0
+			continue
+		}
+
+		shift := qzShift(lhs, rhs, hi)
+		if stuckCount > 0 && stuckCount%10 == 0 {
+			// exceptional shift
+			shift = complex(cmplx.Abs(lhs[hi][hi-1])/cmplx.Abs(rhs[hi-1][hi-1]), 0) + shift
+		}
+
+		doQZIteration(lhs, rhs, lo, hi, shift)
+		iterations++
+		stuckCount++
+	}
+
+	eigenvalues := make([]*GeneralizedEigenvalue, 0, size)
+	for i := 0; i < size; i++ {
+		eigenvalues = append(eigenvalues, &GeneralizedEigenvalue{
+			Alpha:    lhs[i][i],
+			Beta:     rhs[i][i],
+			Infinite: cmplx.Abs(rhs[i][i]) <= epsilon*rhsNorm*float64(size),
+		})
+	}
+
+	return eigenvalues, iterations, nil
+}
@@ -0,0 +1,170 @@
+package cma_methods
+
+import (
+	"cma-lab-go/matrix"
+	"math"
+)
+
+// intervals shorter than this (relative) aren't split in roots isolation
+const ISOLATION_ACCURACY = 1e-14
+
+// p_0 = p, p_1 = p', p_{k+1} = -(p_{k-1} mod p_k), divided by gcd(p, p') for multiple roots
+// every polynomial is scaled to |leading coefficient| = 1, this doesn't change signs
+// the sequence of the zero polynomial is empty, it has no roots to count
+type SturmSequence []Polynomial
+
+// (Left, Right] contains Count distinct roots
+type Interval struct {
+	Left, Right float64
+	Count       int
+}
+
+func normalizeSign(polynomial Polynomial) Polynomial {
+	return polynomial.Scale(1 / math.Abs(polynomial.Leading()))
+}
+
+func NewSturmSequence(polynomial Polynomial) SturmSequence {
+	polynomial = polynomial.chop(GCD_THRESHOLD)
+	if polynomial.Degree() == -1 {
+		return SturmSequence{}
+	}
+	sequence := SturmSequence{normalizeSign(polynomial)}
+	if polynomial.Degree() < 1 {
+		return sequence
+	}
+
+	sequence = append(sequence, normalizeSign(polynomial.Derivative()))
+	for {
+		last := len(sequence) - 1
+		remainder := sequence[last-1].Mod(sequence[last]).Scale(-1)
+
+		// remainder is compared with the dividend scale
+		var scale float64 = 0
+		for _, v := range sequence[last-1] {
+			scale = math.Max(scale, math.Abs(v))
+		}
+		for i, v := range remainder {
+			if math.Abs(v) <= GCD_THRESHOLD*scale {
+				remainder[i] = 0
+			}
+		}
+		remainder = remainder.Trim()
+		if remainder.Degree() == -1 {
+			break
+		}
+
+		sequence = append(sequence, normalizeSign(remainder))
+		if remainder.Degree() == 0 {
+			break
+		}
+	}
+
+	// the last polynomial is gcd(p, p'), all of them vanish at multiple roots,
+	// the quotients form the sequence of the square-free part
+	if divisor := sequence[len(sequence)-1]; divisor.Degree() > 0 {
+		for i := range sequence {
+			sequence[i] = normalizeSign(sequence[i].Div(divisor))
+		}
+	}
+	return sequence
+}
+
+// number of sign changes at the point, zeros are skipped
+func (sequence SturmSequence) signChanges(point float64) int {
+	changes := 0
+	var prev float64 = 0
+	for _, polynomial := range sequence {
+		v := polynomial.Value(point)
+		if v == 0 {
+			continue
+		}
+		if prev != 0 && math.Signbit(prev) != math.Signbit(v) {
+			changes++
+		}
+		prev = v
+	}
+	return changes
+}
+
+// number of distinct real roots in [left, right]
+func (sequence SturmSequence) CountDistinctRoots(left, right float64) int {
+	if len(sequence) == 0 {
+		return 0
+	}
+	count := sequence.signChanges(left) - sequence.signChanges(right)
+	if sequence[0].Value(left) == 0 {
+		count++
+	}
+	return count
+}
+
+func (sequence SturmSequence) isolate(intervals []Interval, left, right float64, count int) []Interval {
+	if count == 0 {
+		return intervals
+	}
+
+	if count == 1 || right-left <= ISOLATION_ACCURACY*math.Max(1, math.Abs(left)+math.Abs(right)) {
+		return append(intervals, Interval{Left: left, Right: right, Count: count})
+	}
+
+	middle := (left + right) / 2
+	leftCount := sequence.signChanges(left) - sequence.signChanges(middle)
+	intervals = sequence.isolate(intervals, left, middle, leftCount)
+	return sequence.isolate(intervals, middle, right, count-leftCount)
+}
+
+// intervals (Left, Right] in ascending order, each of them contains exactly one distinct root
+// (Count > 1 only for clusters unresolvable in double precision)
+func (sequence SturmSequence) IsolateRoots() []Interval {
+	if len(sequence) == 0 || sequence[0].Degree() < 1 {
+		return nil
+	}
+
+	left, right := boundRoots(sequence[0])
+	return sequence.isolate(nil, left, right, sequence.signChanges(left)-sequence.signChanges(right))
+}
+
+// distinct real roots: Sturm isolation and refinement of the square-free part
+func FindPolynomialRootsSturm(polynomial Polynomial) []float64 {
+	if polynomial.Degree() < 1 {
+		return nil
+	}
+	squareFree := polynomial.Div(GCD(polynomial, polynomial.Derivative()))
+
+	var roots []RootResult
+	for _, interval := range NewSturmSequence(squareFree).IsolateRoots() {
+		if squareFree.Value(interval.Right) == 0 || interval.Count > 1 {
+			roots = append(roots, RootResult{Value: interval.Right, Converged: true})
+			continue
+		}
+		roots = appendRoot(roots, squareFree, interval.Left, interval.Right)
+	}
+
+	result := make([]float64, 0, len(roots))
+	for _, root := range roots {
+		result = append(result, root.Value)
+	}
+	return result
+}
+
+// number of real roots in [left, right] counted with multiplicities:
+// distinct roots of every square-free factor q_i are counted i times
+func CountRoots(polynomial Polynomial, left, right float64) int {
+	count := 0
+	for i, factor := range SquareFreeFactorization(polynomial) {
+		count += (i + 1) * NewSturmSequence(factor).CountDistinctRoots(left, right)
+	}
+	return count
+}
+
+// number of real eigenvalues in [left, right] counted with multiplicities
+func CountEigenvalues(squareMatrix *matrix.SquareMatrix, left, right float64) int {
+	polynomial, _, _ := FindPolynomial(squareMatrix)
+	return CountRoots(polynomial, left, right)
+}
+
+// number of distinct real eigenvalues in [left, right]
+func CountDistinctEigenvalues(squareMatrix *matrix.SquareMatrix, left, right float64) int {
+	polynomial, _, _ := FindPolynomial(squareMatrix)
+	return NewSturmSequence(polynomial).CountDistinctRoots(left, right)
+}
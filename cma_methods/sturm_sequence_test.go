@@ -0,0 +1,104 @@
+package cma_methods
+
+import (
+	"cma-lab-go/matrix"
+	"math"
+	"testing"
+)
+
+func TestCountRoots(t *testing.T) {
+	tests := []struct {
+		name        string
+		polynomial  Polynomial
+		left, right float64
+		count       int
+		distinct    int
+	}{
+		// (x - 1)^2 * (x - 2)
+		{"double root", Polynomial{-2, 5, -4, 1}, 0, 3, 3, 2},
+		{"double root on the border", Polynomial{-2, 5, -4, 1}, 1, 1.5, 2, 1},
+		{"simple root only", Polynomial{-2, 5, -4, 1}, 1.5, 3, 1, 1},
+		// (x - 1)^3 * (x + 2)
+		{"triple root", Polynomial{-1, 3, -3, 1}.Mul(Polynomial{2, 1}), -3, 3, 4, 2},
+		// x^2 + 1
+		{"complex roots", Polynomial{1, 0, 1}, -10, 10, 0, 0},
+		{"constant", Polynomial{3}, -10, 10, 0, 0},
+		{"zero", Polynomial{0}, -10, 10, 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if count := CountRoots(test.polynomial, test.left, test.right); count != test.count {
+				t.Errorf("CountRoots(%v, %v, %v) = %v, expected %v", test.polynomial, test.left, test.right, count, test.count)
+			}
+			distinct := NewSturmSequence(test.polynomial).CountDistinctRoots(test.left, test.right)
+			if distinct != test.distinct {
+				t.Errorf("CountDistinctRoots(%v, %v) = %v, expected %v", test.left, test.right, distinct, test.distinct)
+			}
+		})
+	}
+}
+
+func TestCountEigenvalues(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        [][]float64
+		left, right float64
+		count       int
+	}{
+		{"diag(1, 1, 2)", [][]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 2}}, 0, 3, 3},
+		{"diag(1, 1, 2) without 2", [][]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 2}}, 0, 1.5, 2},
+		{"jordan block", [][]float64{{2, 1, 0}, {0, 2, 1}, {0, 0, 2}}, 0, 3, 3},
+		{"rotation", [][]float64{{0, 1}, {-1, 0}}, -2, 2, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			squareMatrix, err := matrix.NewSquareMatrix(test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count := CountEigenvalues(squareMatrix, test.left, test.right); count != test.count {
+				t.Errorf("CountEigenvalues(%v, %v) = %v, expected %v", test.left, test.right, count, test.count)
+			}
+		})
+	}
+}
+
+func TestIsolateRoots(t *testing.T) {
+	tests := []struct {
+		name       string
+		polynomial Polynomial
+		roots      []float64
+	}{
+		// (x - 1)^2 * (x - 2)
+		{"double root", Polynomial{-2, 5, -4, 1}, []float64{1, 2}},
+		{"complex roots", Polynomial{1, 0, 1}, nil},
+		{"constant", Polynomial{3}, nil},
+		{"zero", Polynomial{0}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			intervals := NewSturmSequence(test.polynomial).IsolateRoots()
+			if len(intervals) != len(test.roots) {
+				t.Fatalf("got intervals %v, expected roots %v", intervals, test.roots)
+			}
+			for i, interval := range intervals {
+				if !(interval.Left < test.roots[i] && test.roots[i] <= interval.Right) {
+					t.Errorf("interval %v doesn't contain root %v", interval, test.roots[i])
+				}
+			}
+
+			roots := FindPolynomialRootsSturm(test.polynomial)
+			if len(roots) != len(test.roots) {
+				t.Fatalf("got roots %v, expected %v", roots, test.roots)
+			}
+			for i, root := range roots {
+				if math.Abs(root-test.roots[i]) > 1e-9 {
+					t.Errorf("got roots %v, expected %v", roots, test.roots)
+				}
+			}
+		})
+	}
+}
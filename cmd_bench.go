@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DEFAULT_BENCH_SIZES = "10,20,30,40,50,60,70,80,90,100,110,120,130,140,150,160,170,180,190,200"
+
+var BENCH_OUTPUT_FORMATS = []string{AUTO_FORMAT, TEXT_FORMAT, JSON_FORMAT, CSV_FORMAT, TSV_FORMAT}
+
+// json output of bench, one value per size
+type benchResult struct {
+	Method       string
+	Size         int
+	Repeat       int
+	Milliseconds float64
+	Iterations   int
+}
+
+func parseSizes(value string) ([]int, error) {
+	var sizes []int
+	for _, token := range strings.Split(value, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(token))
+		if err != nil || size < 1 {
+			return nil, usageErrorf("bad size %q in -sizes", token)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+func runBench(args []string) error {
+	opts := newOptions("bench", "")
+	opts.outputFlags(BENCH_OUTPUT_FORMATS)
+	opts.methodFlag(EIGEN_METHODS)
+	opts.iterationFlags()
+	opts.seedFlag()
+	sizesFlag := opts.flags.String("sizes", DEFAULT_BENCH_SIZES, "comma separated sizes of the random matrices")
+	repeat := opts.flags.Int("repeat", 1, "runs per size, the mean time is reported")
+	min := opts.flags.Float64("min", -1000000000, "lower bound of the elements")
+	max := opts.flags.Float64("max", 1000000000, "upper bound of the elements")
+	if err := opts.parse(args); err != nil {
+		return err
+	}
+
+	sizes, err := parseSizes(*sizesFlag)
+	switch {
+	case err != nil:
+		return err
+	case *repeat < 1:
+		return usageErrorf("-repeat must be positive, got %v", *repeat)
+	case !(*min < *max):
+		return usageErrorf("-min must be less than -max, got %v and %v", *min, *max)
+	}
+	rand.Seed(opts.seed)
+
+	out, err := opts.openOutput(BENCH_OUTPUT_FORMATS)
+	if err != nil {
+		return err
+	}
+
+	for _, size := range sizes {
+		var elapsed time.Duration
+		iterations := 0
+		for k := 0; k < *repeat; k++ {
+			// jacobi method works with symmetric matrices only
+			squareMatrix := generateMatrix(size, *min, *max, opts.method == JACOBI_METHOD)
+
+			start := time.Now()
+			solution, solveErr := opts.solveEigen(squareMatrix)
+			elapsed += time.Since(start)
+			if solveErr != nil {
+				err = fmt.Errorf("size %v: %w", size, solveErr)
+				break
+			}
+			iterations += solution.iterations
+		}
+		if err != nil {
+			break
+		}
+
+		milliseconds := float64(elapsed.Microseconds()) / 1000 / float64(*repeat)
+		if out.format == TEXT_FORMAT {
+			out.note("Size = %v => time = %v milliseconds\n", size, milliseconds)
+			continue
+		}
+		out.row([]float64{float64(size), milliseconds, float64(iterations) / float64(*repeat)}, &benchResult{
+			Method:       opts.method,
+			Size:         size,
+			Repeat:       *repeat,
+			Milliseconds: milliseconds,
+			Iterations:   iterations / *repeat,
+		})
+	}
+
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"cma-lab-go/cma_methods"
+	"cma-lab-go/matrix"
+)
+
+const (
+	LEVERRIER_FADDEEV_METHOD = "leverrier-faddeev"
+	KRYLOV_METHOD            = "krylov"
+	HESSENBERG_METHOD        = "hessenberg"
+	// rational arithmetic, slow, but without rounding errors
+	EXACT_METHOD = "exact"
+)
+
+var CHARPOLY_METHODS = []string{DANILEVSKII_METHOD, LEVERRIER_FADDEEV_METHOD, KRYLOV_METHOD, HESSENBERG_METHOD, EXACT_METHOD}
+
+var charpolyMethods = map[string]cma_methods.CharacteristicPolynomialMethod{
+	DANILEVSKII_METHOD:       cma_methods.DANILEVSKII_METHOD,
+	LEVERRIER_FADDEEV_METHOD: cma_methods.LEVERRIER_FADDEEV_METHOD,
+	KRYLOV_METHOD:            cma_methods.KRYLOV_METHOD,
+	HESSENBERG_METHOD:        cma_methods.HESSENBERG_METHOD,
+}
+
+// json output of charpoly, coefficients are stored from the lowest degree
+type polynomialResult struct {
+	Method       string
+	Coefficients []float64
+	Polynomial   string
+}
+
+// det(A - lambda * I) and its string form (exact one for the exact method)
+func findCharacteristicPolynomial(squareMatrix *matrix.SquareMatrix, method string) (cma_methods.Polynomial, string, error) {
+	if method == EXACT_METHOD {
+		exact, err := cma_methods.ExactCharacteristicPolynomial(squareMatrix)
+		if err != nil {
+			return nil, "", err
+		}
+		return exact.Float(), exact.String(), nil
+	}
+
+	polynomial, err := cma_methods.FindCharacteristicPolynomial(squareMatrix, charpolyMethods[method])
+	if err != nil {
+		return nil, "", err
+	}
+	return polynomial, polynomial.String(), nil
+}
+
+func runCharpoly(args []string) error {
+	opts := newOptions("charpoly", "[input ...]")
+	opts.inputFlags()
+	opts.outputFlags(RESULT_OUTPUT_FORMATS)
+	opts.methodFlag(CHARPOLY_METHODS)
+	if err := opts.parse(args); err != nil {
+		return err
+	}
+
+	out, err := opts.openOutput(RESULT_OUTPUT_FORMATS)
+	if err != nil {
+		return err
+	}
+
+	err = opts.forEachMatrix(func(index int, squareMatrix *matrix.SquareMatrix) error {
+		polynomial, text, err := findCharacteristicPolynomial(squareMatrix, opts.method)
+		if err != nil {
+			return err
+		}
+
+		size := len(squareMatrix.Data)
+		out.header("Matrix #%v (%v x %v), %v method\n%v", index, size, size, opts.method, text)
+		out.row(polynomial, &polynomialResult{Method: opts.method, Coefficients: polynomial, Polynomial: text})
+		out.note("\n")
+		return nil
+	})
+
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
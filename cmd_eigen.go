@@ -0,0 +1,238 @@
+package main
+
+import (
+	"cma-lab-go/cma_methods"
+	"cma-lab-go/matrix"
+	"cma-lab-go/utils"
+	"fmt"
+	"math"
+)
+
+const (
+	QR_METHOD          = "qr"
+	QR_BALANCED_METHOD = "qr-balanced"
+	JACOBI_METHOD      = "jacobi"
+	POWER_METHOD       = "power"
+	DANILEVSKII_METHOD = "danilevskii"
+)
+
+var EIGEN_METHODS = []string{QR_METHOD, QR_BALANCED_METHOD, JACOBI_METHOD, POWER_METHOD, DANILEVSKII_METHOD}
+
+// eigenpairs found by any of the methods, eigenvectors[i] is nil if the method didn't find it
+type eigenSolution struct {
+	eigenvalues  []complex128
+	eigenvectors [][]complex128
+	iterations   int
+	// the whole spectrum is found, not only the dominant or the real eigenvalues
+	complete bool
+	// written as it is by json output
+	result interface{}
+	// e.g. the case of the power method
+	details string
+	// eigenvectors are the Schur vectors (qr methods) until useConditionVectors replaces them
+	schurVectors bool
+}
+
+func toComplexVectors(vectors [][]float64) [][]complex128 {
+	result := make([][]complex128, 0, len(vectors))
+	for _, vector := range vectors {
+		if vector == nil {
+			result = append(result, nil)
+			continue
+		}
+		converted := make([]complex128, 0, len(vector))
+		for _, v := range vector {
+			converted = append(converted, complex(v, 0))
+		}
+		result = append(result, converted)
+	}
+	return result
+}
+
+// Schur vectors aren't eigenvectors in general, they are replaced with the inverse iteration vectors
+// of the conditions (found for solution.eigenvalues), in the json result too
+func (solution *eigenSolution) useConditionVectors(conditions []*cma_methods.EigenvalueCondition) {
+	if !solution.schurVectors {
+		return
+	}
+	solution.eigenvectors = make([][]complex128, 0, len(conditions))
+	for _, condition := range conditions {
+		solution.eigenvectors = append(solution.eigenvectors, condition.Right)
+	}
+	solution.schurVectors = false
+	if result, ok := solution.result.(*cma_methods.EigenResult); ok {
+		result.Eigenvectors = solution.realEigenvectors()
+	}
+}
+
+// eigenvectors for the eigen tables, complex ones are nil
+func (solution *eigenSolution) realEigenvectors() [][]float64 {
+	result := make([][]float64, 0, len(solution.eigenvectors))
+	for _, vector := range solution.eigenvectors {
+		var converted []float64
+		for _, v := range vector {
+			if imag(v) != 0 {
+				converted = nil
+				break
+			}
+			converted = append(converted, real(v))
+		}
+		result = append(result, converted)
+	}
+	return result
+}
+
+func isSymmetric(squareMatrix *matrix.SquareMatrix) bool {
+	threshold := cma_methods.ZERO_THRESHOLD * utils.FrobeniusNorm(squareMatrix)
+	for i := range squareMatrix.Data {
+		for j := 0; j < i; j++ {
+			if math.Abs(squareMatrix.Data[i][j]-squareMatrix.Data[j][i]) > threshold {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (opts *options) solveEigen(squareMatrix *matrix.SquareMatrix) (*eigenSolution, error) {
+	size := len(squareMatrix.Data)
+	if size == 1 {
+		// the methods expect at least 2 x 2 matrices
+		value := squareMatrix.Data[0][0]
+		result := cma_methods.NewRealEigenResult([]float64{value}, [][]float64{{1}}, 0)
+		return &eigenSolution{
+			eigenvalues:  result.Eigenvalues,
+			eigenvectors: toComplexVectors(result.Eigenvectors),
+			complete:     true,
+			result:       result,
+		}, nil
+	}
+
+	switch opts.method {
+	case QR_METHOD, QR_BALANCED_METHOD:
+		solve := cma_methods.SolveQRWithOptions
+		if opts.method == QR_BALANCED_METHOD {
+			solve = cma_methods.SolveQRBalancedWithOptions
+		}
+		qrOptions := cma_methods.QROptions{Tolerance: opts.tolerance, MaxIterations: opts.maxIterations}
+		eigenvalues, eigenvectors, iterations, err := solve(squareMatrix, qrOptions)
+		if err != nil {
+			return nil, err
+		}
+		return &eigenSolution{
+			eigenvalues:  eigenvalues,
+			eigenvectors: toComplexVectors(eigenvectors),
+			iterations:   iterations,
+			complete:     true,
+			schurVectors: true,
+			result:       &cma_methods.EigenResult{Eigenvalues: eigenvalues, Eigenvectors: eigenvectors, Iterations: iterations},
+		}, nil
+
+	case JACOBI_METHOD:
+		if !isSymmetric(squareMatrix) {
+			return nil, fmt.Errorf("jacobi method requires a symmetric matrix")
+		}
+		eigenvalues, eigenvectors, sweeps, converged := cma_methods.SolveJacobi(squareMatrix)
+		if !converged {
+			return nil, fmt.Errorf("jacobi method didn't converge in %v sweeps", sweeps)
+		}
+		result := cma_methods.NewRealEigenResult(eigenvalues, eigenvectors, sweeps)
+		return &eigenSolution{
+			eigenvalues:  result.Eigenvalues,
+			eigenvectors: toComplexVectors(eigenvectors),
+			iterations:   sweeps,
+			complete:     true,
+			result:       result,
+		}, nil
+
+	case POWER_METHOD:
+		initApprox := make([]float64, size)
+		initApprox[0] = 1
+		maxIterations := opts.maxIterations
+		if maxIterations == 0 {
+			maxIterations = DEFAULT_POWER_MAX_ITERATIONS
+		}
+		eigenvectors, methodCase, iterations := cma_methods.FindMaxEigenvaluesWithLimit(squareMatrix,
+			matrix.NewColumn(initApprox), maxIterations)
+		if methodCase == cma_methods.STUCK_CASE {
+			return nil, fmt.Errorf("power method got stuck after %v iterations", iterations)
+		}
+		solution := &eigenSolution{
+			iterations: iterations,
+			result:     &cma_methods.PowerMethodResult{Eigenvectors: eigenvectors, Case: methodCase, Iterations: iterations},
+			details:    fmt.Sprintf("%v case", methodCase),
+		}
+		for _, eigenvector := range eigenvectors {
+			solution.eigenvalues = append(solution.eigenvalues, eigenvector.Value)
+			solution.eigenvectors = append(solution.eigenvectors, eigenvector.Vector)
+		}
+		return solution, nil
+
+	case DANILEVSKII_METHOD:
+		eigenvectors, converged := cma_methods.FindEigenvectorsDanilevskii(squareMatrix)
+		if !converged {
+			return nil, fmt.Errorf("roots of the danilevskii polynomial didn't converge")
+		}
+		solution := &eigenSolution{complete: true, result: eigenvectors}
+		recovered := 0
+		for _, eigenvector := range eigenvectors {
+			solution.eigenvalues = append(solution.eigenvalues, eigenvector.Value)
+			solution.eigenvectors = append(solution.eigenvectors, eigenvector.Vector)
+			if eigenvector.InverseIteration {
+				recovered++
+			}
+		}
+		if recovered > 0 {
+			solution.details = fmt.Sprintf("%v eigenvectors recovered by inverse iteration", recovered)
+		}
+		return solution, nil
+	}
+	return nil, usageErrorf("unknown method %q", opts.method)
+}
+
+func runEigen(args []string) error {
+	opts := newOptions("eigen", "[input ...]")
+	opts.inputFlags()
+	opts.outputFlags(RESULT_OUTPUT_FORMATS)
+	opts.methodFlag(EIGEN_METHODS)
+	opts.iterationFlags()
+	if err := opts.parse(args); err != nil {
+		return err
+	}
+
+	out, err := opts.openOutput(RESULT_OUTPUT_FORMATS)
+	if err != nil {
+		return err
+	}
+
+	err = opts.forEachMatrix(func(index int, squareMatrix *matrix.SquareMatrix) error {
+		solution, err := opts.solveEigen(squareMatrix)
+		if err != nil {
+			return err
+		}
+
+		// json has the conditions in the solver results, which support them
+		conditions := cma_methods.FindEigenvalueConditions(squareMatrix, solution.eigenvalues)
+		solution.useConditionVectors(conditions)
+		if result, ok := solution.result.(*cma_methods.EigenResult); ok {
+			result.Conditions = conditions
+		}
+
+		size := len(squareMatrix.Data)
+		out.header("Matrix #%v (%v x %v), %v method, iterations count = %v", index, size, size, opts.method, solution.iterations)
+		if solution.details != "" {
+			out.header("%v", solution.details)
+		}
+		for _, condition := range cma_methods.IllConditioned(conditions) {
+			out.header("eigenvalue %v is ill-conditioned, condition number = %.3g", condition.Value, condition.Condition)
+		}
+		out.eigenTable(solution.eigenvalues, solution.realEigenvectors(), solution.result)
+		out.note("\n")
+		return nil
+	})
+
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
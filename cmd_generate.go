@@ -0,0 +1,65 @@
+package main
+
+import (
+	"cma-lab-go/matrix"
+	"cma-lab-go/utils"
+	"math/rand"
+)
+
+// random elements are uniform in [min, max), symmetric matrices are (A + A^T) / 2
+func generateMatrix(size int, min, max float64, symmetric bool) *matrix.SquareMatrix {
+	squareMatrix := utils.GenerateSquareMatrix(size, min, max)
+	if symmetric {
+		for i := 0; i < size; i++ {
+			for j := 0; j < i; j++ {
+				value := (squareMatrix.Data[i][j] + squareMatrix.Data[j][i]) / 2
+				squareMatrix.Data[i][j], squareMatrix.Data[j][i] = value, value
+			}
+		}
+	}
+	return squareMatrix
+}
+
+func runGenerate(args []string) error {
+	opts := newOptions("generate", "")
+	opts.outputFlags(MATRIX_OUTPUT_FORMATS)
+	opts.seedFlag()
+	size := opts.flags.Int("size", 3, "size of the matrices")
+	count := opts.flags.Int("count", 1, "number of the matrices")
+	min := opts.flags.Float64("min", -10, "lower bound of the elements")
+	max := opts.flags.Float64("max", 10, "upper bound of the elements")
+	symmetric := opts.flags.Bool("symmetric", false, "generate symmetric matrices")
+	if err := opts.parse(args); err != nil {
+		return err
+	}
+
+	switch {
+	case *size < 1:
+		return usageErrorf("-size must be positive, got %v", *size)
+	case *count < 1:
+		return usageErrorf("-count must be positive, got %v", *count)
+	case !(*min < *max):
+		return usageErrorf("-min must be less than -max, got %v and %v", *min, *max)
+	}
+	if format := detectFormat(opts.output, opts.outputFormat); singleMatrixFormats[format] && *count > 1 {
+		return usageErrorf("%v output holds a single matrix, got -count %v", format, *count)
+	}
+	rand.Seed(opts.seed)
+
+	out, err := opts.openOutput(MATRIX_OUTPUT_FORMATS)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < *count; i++ {
+		// the text format is the one FileMatrixReader reads: the dimension, then the rows
+		if out.format == TEXT_FORMAT {
+			out.note("%v\n", *size)
+		}
+		out.writer.WriteMatrix(generateMatrix(*size, *min, *max, *symmetric))
+		if out.format == TEXT_FORMAT {
+			out.note("\n")
+		}
+	}
+	return out.Close()
+}
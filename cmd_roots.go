@@ -0,0 +1,105 @@
+package main
+
+import (
+	"cma-lab-go/cma_methods"
+	"cma-lab-go/matrix"
+	"fmt"
+	"strings"
+)
+
+const (
+	ABERTH_METHOD        = "aberth"
+	DURAND_KERNER_METHOD = "durand-kerner"
+	COMPANION_METHOD     = "companion"
+	// real roots only
+	NEWTON_METHOD = "newton"
+	STURM_METHOD  = "sturm"
+)
+
+var ROOTS_METHODS = []string{ABERTH_METHOD, DURAND_KERNER_METHOD, COMPANION_METHOD, NEWTON_METHOD, STURM_METHOD}
+
+func toComplex(values []float64) []complex128 {
+	result := make([]complex128, 0, len(values))
+	for _, v := range values {
+		result = append(result, complex(v, 0))
+	}
+	return result
+}
+
+// roots and number of iterations (total one for the real root finders),
+// qrOptions are the settings of the companion matrix method
+func findRoots(polynomial cma_methods.Polynomial, method string, qrOptions cma_methods.QROptions) ([]complex128, int, error) {
+	switch method {
+	case ABERTH_METHOD, DURAND_KERNER_METHOD:
+		find := cma_methods.FindComplexPolynomialRoots
+		if method == DURAND_KERNER_METHOD {
+			find = cma_methods.FindComplexPolynomialRootsDurandKerner
+		}
+		roots, iterations, converged := find(polynomial)
+		if !converged {
+			return nil, iterations, fmt.Errorf("%v method didn't converge in %v iterations", method, iterations)
+		}
+		return roots, iterations, nil
+	case COMPANION_METHOD:
+		return cma_methods.FindPolynomialRootsCompanionWithOptions(polynomial, qrOptions)
+	case NEWTON_METHOD:
+		var roots []complex128
+		iterations := 0
+		for _, root := range cma_methods.FindPolynomialRootsDetailed(polynomial) {
+			if !root.Converged {
+				return nil, iterations, fmt.Errorf("root near %v didn't converge in %v iterations", root.Value, root.Iterations)
+			}
+			roots = append(roots, complex(root.Value, 0))
+			iterations += root.Iterations
+		}
+		return roots, iterations, nil
+	case STURM_METHOD:
+		return toComplex(cma_methods.FindPolynomialRootsSturm(polynomial)), 0, nil
+	}
+	return nil, 0, usageErrorf("unknown method %q", method)
+}
+
+func runRoots(args []string) error {
+	opts := newOptions("roots", "[input ...]")
+	opts.inputFlags()
+	opts.outputFlags(RESULT_OUTPUT_FORMATS)
+	opts.methodFlag(ROOTS_METHODS)
+	opts.iterationFlags()
+	polynomialMethod := opts.flags.String("polynomial", DANILEVSKII_METHOD,
+		"characteristic polynomial method: "+strings.Join(CHARPOLY_METHODS, ", "))
+	if err := opts.parse(args); err != nil {
+		return err
+	}
+	if err := checkChoice("polynomial method", *polynomialMethod, CHARPOLY_METHODS); err != nil {
+		return err
+	}
+
+	out, err := opts.openOutput(RESULT_OUTPUT_FORMATS)
+	if err != nil {
+		return err
+	}
+
+	err = opts.forEachMatrix(func(index int, squareMatrix *matrix.SquareMatrix) error {
+		polynomial, text, err := findCharacteristicPolynomial(squareMatrix, *polynomialMethod)
+		if err != nil {
+			return err
+		}
+		qrOptions := cma_methods.QROptions{Tolerance: opts.tolerance, MaxIterations: opts.maxIterations}
+		roots, iterations, err := findRoots(polynomial, opts.method, qrOptions)
+		if err != nil {
+			return err
+		}
+
+		size := len(squareMatrix.Data)
+		out.header("Matrix #%v (%v x %v), %v method, iterations count = %v\n%v",
+			index, size, size, opts.method, iterations, text)
+		out.eigenTable(roots, nil, &cma_methods.ComplexRootsResult{Roots: roots, Iterations: iterations, Converged: true})
+		out.note("\n")
+		return nil
+	})
+
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"cma-lab-go/cma_methods"
+	"cma-lab-go/matrix"
+	"cma-lab-go/utils"
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+var VERIFY_OUTPUT_FORMATS = []string{AUTO_FORMAT, TEXT_FORMAT, JSON_FORMAT}
+
+// json output of verify, residuals are nil for eigenvalues without eigenvectors
+// (and for non-finite values, json has no NaN), such eigenvalues fail the check
+type verifyReport struct {
+	Matrix      int
+	Method      string
+	Residuals   []*float64
+	MaxResidual *float64
+	// |sum of eigenvalues - trace|, only for the methods finding the whole spectrum
+	TraceError *float64
+	Passed     bool
+}
+
+func finiteOrNil(value float64) *float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil
+	}
+	return &value
+}
+
+// ||A * x - lambda * x|| / (||A|| * ||x||)
+func relativeResidual(squareMatrix *matrix.SquareMatrix, eigenvalue complex128, eigenvector []complex128, scale float64) float64 {
+	var residual, norm float64 = 0, 0
+	for i, row := range squareMatrix.Data {
+		var sum complex128 = 0
+		for j, v := range row {
+			sum += complex(v, 0) * eigenvector[j]
+		}
+		residual += math.Pow(cmplx.Abs(sum-eigenvalue*eigenvector[i]), 2)
+		norm += math.Pow(cmplx.Abs(eigenvector[i]), 2)
+	}
+	if norm == 0 {
+		return math.Inf(1)
+	}
+	return math.Sqrt(residual) / (scale * math.Sqrt(norm))
+}
+
+func (opts *options) verify(index int, squareMatrix *matrix.SquareMatrix, solution *eigenSolution, tolerance float64) *verifyReport {
+	size := len(squareMatrix.Data)
+	scale := utils.FrobeniusNorm(squareMatrix)
+	if scale == 0 {
+		scale = 1
+	}
+
+	// eigenvalues are checked with the same eigenvectors, which eigen command prints
+	if solution.schurVectors {
+		solution.useConditionVectors(cma_methods.FindEigenvalueConditions(squareMatrix, solution.eigenvalues))
+	}
+	eigenvectors := solution.eigenvectors
+
+	report := &verifyReport{Matrix: index, Method: opts.method, Passed: true}
+	maxResidual := 0.0
+	for i, eigenvalue := range solution.eigenvalues {
+		if i >= len(eigenvectors) || len(eigenvectors[i]) != size {
+			report.Residuals = append(report.Residuals, nil)
+			report.Passed = false
+			maxResidual = math.Inf(1)
+			continue
+		}
+		residual := relativeResidual(squareMatrix, eigenvalue, eigenvectors[i], scale)
+		report.Residuals = append(report.Residuals, finiteOrNil(residual))
+		if !(residual <= tolerance) {
+			report.Passed = false
+		}
+		maxResidual = math.Max(maxResidual, residual)
+	}
+	report.MaxResidual = finiteOrNil(maxResidual)
+
+	if solution.complete {
+		var sum complex128 = 0
+		for _, eigenvalue := range solution.eigenvalues {
+			sum += eigenvalue
+		}
+		trace := 0.0
+		for i := range squareMatrix.Data {
+			trace += squareMatrix.Data[i][i]
+		}
+		traceError := cmplx.Abs(sum-complex(trace, 0)) / (float64(size) * scale)
+		report.TraceError = finiteOrNil(traceError)
+		if len(solution.eigenvalues) != size || !(traceError <= tolerance) {
+			report.Passed = false
+		}
+	}
+	return report
+}
+
+func formatReportValue(value *float64) string {
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.3g", *value)
+}
+
+func runVerify(args []string) error {
+	opts := newOptions("verify", "[input ...]")
+	opts.inputFlags()
+	opts.outputFlags(VERIFY_OUTPUT_FORMATS)
+	opts.methodFlag(EIGEN_METHODS)
+	opts.iterationFlags()
+	tolerance := opts.flags.Float64("residual-tol", DEFAULT_RESIDUAL_TOLERANCE,
+		"maximum relative residual ||A x - lambda x|| / (||A|| ||x||) of an eigenpair")
+	if err := opts.parse(args); err != nil {
+		return err
+	}
+	if !(*tolerance > 0) {
+		return usageErrorf("-residual-tol must be positive, got %v", *tolerance)
+	}
+
+	out, err := opts.openOutput(VERIFY_OUTPUT_FORMATS)
+	if err != nil {
+		return err
+	}
+
+	total, failed := 0, 0
+	err = opts.forEachMatrix(func(index int, squareMatrix *matrix.SquareMatrix) error {
+		solution, err := opts.solveEigen(squareMatrix)
+		if err != nil {
+			return err
+		}
+
+		report := opts.verify(index, squareMatrix, solution, *tolerance)
+		total++
+		status := "OK"
+		if !report.Passed {
+			failed++
+			status = "FAILED"
+		}
+
+		if out.json != nil {
+			out.json.WriteValue(report)
+			return nil
+		}
+		size := len(squareMatrix.Data)
+		out.note("Matrix #%v (%v x %v), %v method: max residual = %v, trace error = %v, %v\n", index, size, size,
+			opts.method, formatReportValue(report.MaxResidual), formatReportValue(report.TraceError), status)
+		for i, residual := range report.Residuals {
+			if residual == nil || *residual > *tolerance {
+				out.note("  eigenvalue %v: residual = %v\n", solution.eigenvalues[i], formatReportValue(residual))
+			}
+		}
+		return nil
+	})
+
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && failed > 0 {
+		err = fmt.Errorf("%w: %v of %v matrices", errVerifyFailed, failed, total)
+	}
+	return err
+}
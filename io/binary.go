@@ -0,0 +1,453 @@
+package io
+
+import (
+	"bufio"
+	"cma-lab-go/matrix"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	goio "io"
+	"math"
+	"os"
+)
+
+// every object is a 32 bytes little-endian header followed by its data
+// header: magic "CMAB" (4 bytes), version (uint16), element type (uint8), flags (uint8),
+// kind (uint8), 3 reserved bytes, rows (uint32), columns (uint32), CRC-32 (IEEE) of the data (uint32)
+// and 8 reserved bytes, so that float64 data is aligned
+// symmetric flag means that only the lower triangle is stored by rows
+// zstd isn't in the standard library, so only gzip compression of the whole stream is supported
+const (
+	BINARY_MAGIC       = "CMAB"
+	BINARY_VERSION     = 1
+	BINARY_HEADER_SIZE = 32
+)
+
+const BINARY_SYMMETRIC_FLAG = 1
+
+type BinaryElementType uint8
+
+const (
+	BINARY_FLOAT64 BinaryElementType = 1
+	BINARY_FLOAT32 BinaryElementType = 2
+)
+
+type BinaryKind uint8
+
+const (
+	BINARY_MATRIX BinaryKind = iota
+	BINARY_ROW
+	BINARY_COLUMN
+)
+
+func (kind BinaryKind) String() string {
+	switch kind {
+	case BINARY_MATRIX:
+		return MATRIX_TAG
+	case BINARY_ROW:
+		return ROW_TAG
+	case BINARY_COLUMN:
+		return COLUMN_TAG
+	}
+	return fmt.Sprintf("BinaryKind(%d)", int(kind))
+}
+
+type BinaryHeader struct {
+	Kind        BinaryKind
+	ElementType BinaryElementType
+	Symmetric   bool
+	Rows, Cols  int
+	Checksum    uint32
+}
+
+func (header *BinaryHeader) elementSize() int {
+	if header.ElementType == BINARY_FLOAT32 {
+		return 4
+	}
+	return 8
+}
+
+// number of stored elements
+func (header *BinaryHeader) count() int {
+	if header.Symmetric {
+		return header.Rows * (header.Rows + 1) / 2
+	}
+	return header.Rows * header.Cols
+}
+
+func (header *BinaryHeader) dataSize() int {
+	return header.count() * header.elementSize()
+}
+
+func (header *BinaryHeader) encode() []byte {
+	result := make([]byte, BINARY_HEADER_SIZE)
+	copy(result, BINARY_MAGIC)
+	binary.LittleEndian.PutUint16(result[4:], BINARY_VERSION)
+	result[6] = byte(header.ElementType)
+	if header.Symmetric {
+		result[7] |= BINARY_SYMMETRIC_FLAG
+	}
+	result[8] = byte(header.Kind)
+	binary.LittleEndian.PutUint32(result[12:], uint32(header.Rows))
+	binary.LittleEndian.PutUint32(result[16:], uint32(header.Cols))
+	binary.LittleEndian.PutUint32(result[20:], header.Checksum)
+	return result
+}
+
+func decodeBinaryHeader(data []byte) (*BinaryHeader, error) {
+	if string(data[:4]) != BINARY_MAGIC {
+		return nil, fmt.Errorf("not a binary matrix header")
+	}
+	if version := binary.LittleEndian.Uint16(data[4:]); version != BINARY_VERSION {
+		return nil, fmt.Errorf("unsupported binary format version %v", version)
+	}
+
+	header := &BinaryHeader{
+		ElementType: BinaryElementType(data[6]),
+		Symmetric:   data[7]&BINARY_SYMMETRIC_FLAG != 0,
+		Kind:        BinaryKind(data[8]),
+		Rows:        int(binary.LittleEndian.Uint32(data[12:])),
+		Cols:        int(binary.LittleEndian.Uint32(data[16:])),
+		Checksum:    binary.LittleEndian.Uint32(data[20:]),
+	}
+
+	switch header.ElementType {
+	case BINARY_FLOAT64, BINARY_FLOAT32:
+	default:
+		return nil, fmt.Errorf("unsupported element type %v", header.ElementType)
+	}
+
+	switch header.Kind {
+	case BINARY_MATRIX:
+		if header.Rows != header.Cols {
+			return nil, fmt.Errorf("matrix isn't square, got %v x %v", header.Rows, header.Cols)
+		}
+	case BINARY_ROW, BINARY_COLUMN:
+		if header.Symmetric {
+			return nil, fmt.Errorf("vector can't be symmetric")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported kind %v", header.Kind)
+	}
+
+	if header.Rows > MAX_DIMENSION || header.Cols > MAX_DIMENSION {
+		return nil, fmt.Errorf("dimension %v x %v is too big, maximum is %v", header.Rows, header.Cols, MAX_DIMENSION)
+	}
+	return header, nil
+}
+
+// dimension of the matrix or the length of the vector
+func (header *BinaryHeader) dimension() int {
+	if header.Kind == BINARY_ROW {
+		return header.Cols
+	}
+	return header.Rows
+}
+
+func (header *BinaryHeader) checkKind(kind BinaryKind, size int) error {
+	if header.Kind != kind {
+		return fmt.Errorf("expected object of kind %v, got %v", kind, header.Kind)
+	}
+	if header.dimension() != size {
+		return fmt.Errorf("expected dimension %v, got %v", size, header.dimension())
+	}
+	return nil
+}
+
+func (header *BinaryHeader) verify(data []byte) error {
+	if checksum := crc32.ChecksumIEEE(data); checksum != header.Checksum {
+		return fmt.Errorf("checksum mismatch: expected %08x, got %08x", header.Checksum, checksum)
+	}
+	return nil
+}
+
+// all elements in row-major order, symmetric matrices are expanded
+func (header *BinaryHeader) decode(data []byte) []float64 {
+	size := header.elementSize()
+	stored := make([]float64, header.count())
+	for k := range stored {
+		if size == 4 {
+			stored[k] = float64(math.Float32frombits(binary.LittleEndian.Uint32(data[k*size:])))
+		} else {
+			stored[k] = math.Float64frombits(binary.LittleEndian.Uint64(data[k*size:]))
+		}
+	}
+
+	if !header.Symmetric {
+		return stored
+	}
+
+	n := header.Rows
+	result := make([]float64, n*n)
+	k := 0
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			result[i*n+j] = stored[k]
+			result[j*n+i] = stored[k]
+			k++
+		}
+	}
+	return result
+}
+
+func toRows(data []float64, size int) [][]float64 {
+	result := make([][]float64, size)
+	for i := range result {
+		result[i] = data[i*size : (i+1)*size : (i+1)*size]
+	}
+	return result
+}
+
+type BinaryOptions struct {
+	ElementType BinaryElementType
+	// store only the lower triangle of matrices, which are exactly symmetric
+	PackSymmetric bool
+}
+
+var DefaultBinaryOptions = BinaryOptions{ElementType: BINARY_FLOAT64, PackSymmetric: true}
+
+// writes objects back-to-back, the first error is kept and returned by Close
+type BinaryMatrixWriter struct {
+	writer  *bufio.Writer
+	closers []goio.Closer
+	options BinaryOptions
+
+	err error
+}
+
+// writes to any stream (e.g. os.Stdout), Close flushes, but doesn't close it
+func NewStreamBinaryMatrixWriter(stream goio.Writer, options BinaryOptions) *BinaryMatrixWriter {
+	return &BinaryMatrixWriter{writer: bufio.NewWriter(stream), options: options}
+}
+
+func NewBinaryMatrixWriter(filename string, options BinaryOptions) (*BinaryMatrixWriter, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return &BinaryMatrixWriter{options: options, err: err}, err
+	}
+
+	matrixWriter := NewStreamBinaryMatrixWriter(file, options)
+	matrixWriter.closers = []goio.Closer{file}
+	return matrixWriter, nil
+}
+
+// the whole stream is gzip compressed
+func NewGzipBinaryMatrixWriter(filename string, options BinaryOptions) (*BinaryMatrixWriter, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return &BinaryMatrixWriter{options: options, err: err}, err
+	}
+
+	compressor := gzip.NewWriter(file)
+	matrixWriter := NewStreamBinaryMatrixWriter(compressor, options)
+	// the compressor is closed before the file
+	matrixWriter.closers = []goio.Closer{compressor, file}
+	return matrixWriter, nil
+}
+
+func (writer *BinaryMatrixWriter) keepError(err error) {
+	if writer.err == nil {
+		writer.err = err
+	}
+}
+
+func (writer *BinaryMatrixWriter) write(header *BinaryHeader, values []float64) {
+	if writer.options.ElementType == 0 {
+		header.ElementType = BINARY_FLOAT64
+	} else {
+		header.ElementType = writer.options.ElementType
+	}
+
+	size := header.elementSize()
+	data := make([]byte, len(values)*size)
+	for k, v := range values {
+		if size == 4 {
+			binary.LittleEndian.PutUint32(data[k*size:], math.Float32bits(float32(v)))
+		} else {
+			binary.LittleEndian.PutUint64(data[k*size:], math.Float64bits(v))
+		}
+	}
+	header.Checksum = crc32.ChecksumIEEE(data)
+
+	if _, err := writer.writer.Write(header.encode()); err != nil {
+		writer.keepError(err)
+		return
+	}
+	_, err := writer.writer.Write(data)
+	writer.keepError(err)
+}
+
+func isSymmetric(squareMatrix *matrix.SquareMatrix) bool {
+	for i := range squareMatrix.Data {
+		for j := 0; j < i; j++ {
+			if squareMatrix.Data[i][j] != squareMatrix.Data[j][i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (writer *BinaryMatrixWriter) WriteMatrix(squareMatrix *matrix.SquareMatrix) {
+	size := len(squareMatrix.Data)
+	header := &BinaryHeader{Kind: BINARY_MATRIX, Rows: size, Cols: size}
+
+	var values []float64
+	if writer.options.PackSymmetric && isSymmetric(squareMatrix) {
+		header.Symmetric = true
+		values = make([]float64, 0, size*(size+1)/2)
+		for i := 0; i < size; i++ {
+			values = append(values, squareMatrix.Data[i][:i+1]...)
+		}
+	} else {
+		values = make([]float64, 0, size*size)
+		for _, row := range squareMatrix.Data {
+			values = append(values, row...)
+		}
+	}
+	writer.write(header, values)
+}
+
+func (writer *BinaryMatrixWriter) WriteRow(row *matrix.Row) {
+	writer.write(&BinaryHeader{Kind: BINARY_ROW, Rows: 1, Cols: len(row.Data)}, row.Data)
+}
+
+func (writer *BinaryMatrixWriter) WriteColumn(column *matrix.Column) {
+	writer.write(&BinaryHeader{Kind: BINARY_COLUMN, Rows: len(column.Data), Cols: 1}, column.Data)
+}
+
+func (writer *BinaryMatrixWriter) Close() error {
+	if writer.writer != nil {
+		writer.keepError(writer.writer.Flush())
+	}
+	for _, closer := range writer.closers {
+		writer.keepError(closer.Close())
+	}
+	return writer.err
+}
+
+// reads objects one by one, ReadDimension reads the header of the next object
+// returns io.EOF, if there are no more objects
+type BinaryMatrixReader struct {
+	reader  *bufio.Reader
+	closers []goio.Closer
+
+	pending *BinaryHeader
+}
+
+// reads from any stream (e.g. os.Stdin), Close doesn't close it
+func NewStreamBinaryMatrixReader(stream goio.Reader) *BinaryMatrixReader {
+	return &BinaryMatrixReader{reader: bufio.NewReader(stream)}
+}
+
+func NewBinaryMatrixReader(filename string) (*BinaryMatrixReader, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return &BinaryMatrixReader{}, err
+	}
+
+	matrixReader := NewStreamBinaryMatrixReader(file)
+	matrixReader.closers = []goio.Closer{file}
+	return matrixReader, nil
+}
+
+func NewGzipBinaryMatrixReader(filename string) (*BinaryMatrixReader, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return &BinaryMatrixReader{}, err
+	}
+
+	decompressor, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return &BinaryMatrixReader{}, err
+	}
+
+	matrixReader := NewStreamBinaryMatrixReader(decompressor)
+	matrixReader.closers = []goio.Closer{decompressor, file}
+	return matrixReader, nil
+}
+
+// header of the next object, it stays pending until its data is read
+func (reader *BinaryMatrixReader) ReadHeader() (*BinaryHeader, error) {
+	if reader.pending != nil {
+		return reader.pending, nil
+	}
+
+	data := make([]byte, BINARY_HEADER_SIZE)
+	if _, err := goio.ReadFull(reader.reader, data); err != nil {
+		if err == goio.EOF {
+			return nil, goio.EOF
+		}
+		return nil, fmt.Errorf("binary header: %w", err)
+	}
+
+	header, err := decodeBinaryHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	reader.pending = header
+	return header, nil
+}
+
+func (reader *BinaryMatrixReader) readValues(kind BinaryKind, size int) ([]float64, error) {
+	header, err := reader.ReadHeader()
+	if err != nil {
+		return nil, err
+	}
+	if err := header.checkKind(kind, size); err != nil {
+		return nil, err
+	}
+	reader.pending = nil
+
+	// the buffer grows with the data read, a short file doesn't allocate the whole header size
+	dataSize := header.dataSize()
+	data, err := goio.ReadAll(goio.LimitReader(reader.reader, int64(dataSize)))
+	if err != nil {
+		return nil, fmt.Errorf("binary data of %v bytes: %w", dataSize, err)
+	}
+	if len(data) < dataSize {
+		return nil, fmt.Errorf("binary data of %v bytes: %w", dataSize, goio.ErrUnexpectedEOF)
+	}
+	if err := header.verify(data); err != nil {
+		return nil, err
+	}
+	return header.decode(data), nil
+}
+
+func (reader *BinaryMatrixReader) ReadDimension() (int, error) {
+	header, err := reader.ReadHeader()
+	if err != nil {
+		return 0, err
+	}
+	return header.dimension(), nil
+}
+
+func (reader *BinaryMatrixReader) ReadMatrix(size int) (*matrix.SquareMatrix, error) {
+	values, err := reader.readValues(BINARY_MATRIX, size)
+	if err != nil {
+		return &matrix.SquareMatrix{}, err
+	}
+	return matrix.NewSquareMatrix(toRows(values, size))
+}
+
+func (reader *BinaryMatrixReader) ReadRow(size int) (*matrix.Row, error) {
+	row, err := reader.readValues(BINARY_ROW, size)
+	return matrix.NewRow(row), err
+}
+
+func (reader *BinaryMatrixReader) ReadColumn(size int) (*matrix.Column, error) {
+	col, err := reader.readValues(BINARY_COLUMN, size)
+	return matrix.NewColumn(col), err
+}
+
+func (reader *BinaryMatrixReader) Close() error {
+	var result error
+	for _, closer := range reader.closers {
+		if err := closer.Close(); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
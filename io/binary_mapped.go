@@ -0,0 +1,133 @@
+package io
+
+import (
+	"cma-lab-go/matrix"
+	"fmt"
+	goio "io"
+	"unsafe"
+)
+
+var littleEndianHost = func() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+// reads objects of the binary format from the memory mapped file (see mapFile),
+// general float64 matrices point into the mapping without copying,
+// so they must not be used after Close (writes to them aren't written to the file)
+type MmapMatrixReader struct {
+	data   []byte
+	offset int
+
+	pending *BinaryHeader
+}
+
+func NewMmapMatrixReader(filename string) (*MmapMatrixReader, error) {
+	data, err := mapFile(filename)
+	if err != nil {
+		return &MmapMatrixReader{}, err
+	}
+	return &MmapMatrixReader{data: data}, nil
+}
+
+func (reader *MmapMatrixReader) ReadHeader() (*BinaryHeader, error) {
+	if reader.pending != nil {
+		return reader.pending, nil
+	}
+
+	if reader.offset == len(reader.data) {
+		return nil, goio.EOF
+	}
+	if len(reader.data)-reader.offset < BINARY_HEADER_SIZE {
+		return nil, fmt.Errorf("binary header: %w", goio.ErrUnexpectedEOF)
+	}
+
+	header, err := decodeBinaryHeader(reader.data[reader.offset : reader.offset+BINARY_HEADER_SIZE])
+	if err != nil {
+		return nil, err
+	}
+	reader.pending = header
+	return header, nil
+}
+
+// data of the pending object
+func (reader *MmapMatrixReader) next(kind BinaryKind, size int) (*BinaryHeader, []byte, error) {
+	header, err := reader.ReadHeader()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := header.checkKind(kind, size); err != nil {
+		return nil, nil, err
+	}
+
+	start := reader.offset + BINARY_HEADER_SIZE
+	end := start + header.dataSize()
+	if end > len(reader.data) {
+		return nil, nil, fmt.Errorf("binary data of %v bytes: %w", header.dataSize(), goio.ErrUnexpectedEOF)
+	}
+	data := reader.data[start:end:end]
+	if err := header.verify(data); err != nil {
+		return nil, nil, err
+	}
+
+	reader.pending = nil
+	reader.offset = end
+	return header, data, nil
+}
+
+// float64 view of the data, if its layout allows that
+func float64View(header *BinaryHeader, data []byte) ([]float64, bool) {
+	if header.ElementType != BINARY_FLOAT64 || header.Symmetric || !littleEndianHost {
+		return nil, false
+	}
+	if len(data) == 0 {
+		return []float64{}, true
+	}
+	if uintptr(unsafe.Pointer(&data[0]))%unsafe.Alignof(float64(0)) != 0 {
+		return nil, false
+	}
+	return unsafe.Slice((*float64)(unsafe.Pointer(&data[0])), len(data)/8), true
+}
+
+func (reader *MmapMatrixReader) readValues(kind BinaryKind, size int) ([]float64, error) {
+	header, data, err := reader.next(kind, size)
+	if err != nil {
+		return nil, err
+	}
+	if values, ok := float64View(header, data); ok {
+		return values, nil
+	}
+	return header.decode(data), nil
+}
+
+func (reader *MmapMatrixReader) ReadDimension() (int, error) {
+	header, err := reader.ReadHeader()
+	if err != nil {
+		return 0, err
+	}
+	return header.dimension(), nil
+}
+
+func (reader *MmapMatrixReader) ReadMatrix(size int) (*matrix.SquareMatrix, error) {
+	values, err := reader.readValues(BINARY_MATRIX, size)
+	if err != nil {
+		return &matrix.SquareMatrix{}, err
+	}
+	return matrix.NewSquareMatrix(toRows(values, size))
+}
+
+func (reader *MmapMatrixReader) ReadRow(size int) (*matrix.Row, error) {
+	row, err := reader.readValues(BINARY_ROW, size)
+	return matrix.NewRow(row), err
+}
+
+func (reader *MmapMatrixReader) ReadColumn(size int) (*matrix.Column, error) {
+	col, err := reader.readValues(BINARY_COLUMN, size)
+	return matrix.NewColumn(col), err
+}
+
+func (reader *MmapMatrixReader) Close() error {
+	data := reader.data
+	reader.data = nil
+	return unmapFile(data)
+}
@@ -0,0 +1,178 @@
+package io
+
+import (
+	"bytes"
+	"cma-lab-go/matrix"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	goio "io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type binaryReader struct {
+	name string
+	open func(filename string) (MatrixReader, error)
+}
+
+var (
+	streamBinaryReader = binaryReader{"stream", func(filename string) (MatrixReader, error) { return NewBinaryMatrixReader(filename) }}
+	mmapBinaryReader   = binaryReader{"mmap", func(filename string) (MatrixReader, error) { return NewMmapMatrixReader(filename) }}
+	gzipBinaryReader   = binaryReader{"gzip", func(filename string) (MatrixReader, error) { return NewGzipBinaryMatrixReader(filename) }}
+)
+
+// header with the valid checksum of data followed by data
+func binaryObject(header *BinaryHeader, data []byte) []byte {
+	header.Checksum = crc32.ChecksumIEEE(data)
+	return append(header.encode(), data...)
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	symmetric := [][]float64{{2, -1, 0}, {-1, 2, -1}, {0, -1, 2}}
+	general := [][]float64{{1, 2}, {3, 0.25}}
+	vector := []float64{1, -2.5, 4}
+	tests := []struct {
+		name    string
+		options BinaryOptions
+		gzip    bool
+	}{
+		{"default", DefaultBinaryOptions, false},
+		{"unpacked", BinaryOptions{ElementType: BINARY_FLOAT64}, false},
+		{"float32", BinaryOptions{ElementType: BINARY_FLOAT32, PackSymmetric: true}, false},
+		{"gzip", DefaultBinaryOptions, true},
+	}
+
+	for _, test := range tests {
+		filename := filepath.Join(t.TempDir(), "matrices.bin")
+		var writer *BinaryMatrixWriter
+		var err error
+		if test.gzip {
+			writer, err = NewGzipBinaryMatrixWriter(filename, test.options)
+		} else {
+			writer, err = NewBinaryMatrixWriter(filename, test.options)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		symmetricMatrix, _ := matrix.NewSquareMatrix(symmetric)
+		generalMatrix, _ := matrix.NewSquareMatrix(general)
+		writer.WriteMatrix(symmetricMatrix)
+		writer.WriteMatrix(generalMatrix)
+		writer.WriteRow(matrix.NewRow(vector))
+		writer.WriteColumn(matrix.NewColumn(vector))
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		readers := []binaryReader{streamBinaryReader, mmapBinaryReader}
+		if test.gzip {
+			readers = []binaryReader{gzipBinaryReader}
+		}
+		for _, open := range readers {
+			t.Run(test.name+" "+open.name, func(t *testing.T) {
+				reader, err := open.open(filename)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer reader.Close()
+
+				for _, data := range [][][]float64{symmetric, general} {
+					size, err := reader.ReadDimension()
+					if err != nil {
+						t.Fatal(err)
+					}
+					actual, err := reader.ReadMatrix(size)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !reflect.DeepEqual(actual.Data, data) {
+						t.Errorf("got %v, expected %v", actual.Data, data)
+					}
+				}
+				row, err := reader.ReadRow(len(vector))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(row.Data, vector) {
+					t.Errorf("got %v, expected %v", row.Data, vector)
+				}
+				// the kind is checked
+				if _, err := reader.ReadRow(len(vector)); err == nil || !strings.Contains(err.Error(), "expected object of kind row") {
+					t.Errorf("got error %v for a column read as a row", err)
+				}
+				column, err := reader.ReadColumn(len(vector))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(column.Data, vector) {
+					t.Errorf("got %v, expected %v", column.Data, vector)
+				}
+				if _, err := reader.ReadDimension(); err != goio.EOF {
+					t.Errorf("got error %v after the last object", err)
+				}
+			})
+		}
+	}
+}
+
+func TestBinaryErrors(t *testing.T) {
+	data := make([]byte, 4*8)
+	valid := func() *BinaryHeader {
+		return &BinaryHeader{Kind: BINARY_MATRIX, ElementType: BINARY_FLOAT64, Rows: 2, Cols: 2}
+	}
+	withByte := func(offset int, value byte) []byte {
+		object := binaryObject(valid(), data)
+		object[offset] = value
+		return object
+	}
+	withUint32 := func(offset int, value uint32) []byte {
+		object := binaryObject(valid(), data)
+		binary.LittleEndian.PutUint32(object[offset:], value)
+		return object
+	}
+	tests := []struct {
+		name    string
+		content []byte
+		message string
+	}{
+		{"bad magic", withByte(0, 'X'), "not a binary matrix header"},
+		{"unsupported version", withByte(4, 2), "unsupported binary format version 2"},
+		{"unsupported element type", withByte(6, 3), "unsupported element type"},
+		{"unsupported kind", withByte(8, 7), "unsupported kind"},
+		{"not square", withUint32(16, 3), "isn't square"},
+		{"symmetric vector", binaryObject(&BinaryHeader{Kind: BINARY_ROW, ElementType: BINARY_FLOAT64, Symmetric: true, Rows: 1, Cols: 1}, nil), "can't be symmetric"},
+		{"huge dimension", binaryObject(&BinaryHeader{Kind: BINARY_ROW, ElementType: BINARY_FLOAT64, Rows: 1, Cols: 1 << 20}, nil), "too big"},
+		{"huge data", binaryObject(&BinaryHeader{Kind: BINARY_MATRIX, ElementType: BINARY_FLOAT64, Rows: MAX_DIMENSION, Cols: MAX_DIMENSION}, nil), "unexpected EOF"},
+		{"truncated header", binaryObject(valid(), data)[:BINARY_HEADER_SIZE-1], "unexpected EOF"},
+		{"truncated data", binaryObject(valid(), data)[:BINARY_HEADER_SIZE+31], "unexpected EOF"},
+		{"checksum mismatch", withByte(BINARY_HEADER_SIZE, 1), "checksum mismatch"},
+	}
+
+	for _, test := range tests {
+		for _, open := range []binaryReader{streamBinaryReader, mmapBinaryReader} {
+			t.Run(test.name+" "+open.name, func(t *testing.T) {
+				reader, err := open.open(writeTempFile(t, "matrix.bin", test.content))
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer reader.Close()
+				size, err := reader.ReadDimension()
+				if err == nil {
+					_, err = reader.ReadMatrix(size)
+				}
+				if err == nil || !strings.Contains(err.Error(), test.message) {
+					t.Errorf("got error %v, expected %q", err, test.message)
+				}
+			})
+		}
+	}
+
+	// truncation is reported with the standard error
+	reader := NewStreamBinaryMatrixReader(bytes.NewReader(binaryObject(valid(), data)[:40]))
+	if _, err := reader.ReadMatrix(2); !errors.Is(err, goio.ErrUnexpectedEOF) {
+		t.Errorf("got error %v, expected unexpected EOF", err)
+	}
+}
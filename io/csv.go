@@ -0,0 +1,340 @@
+package io
+
+import (
+	"cma-lab-go/matrix"
+	"encoding/csv"
+	"fmt"
+	goio "io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	CSV_DELIMITER = ','
+	TSV_DELIMITER = '\t'
+)
+
+type CSVOptions struct {
+	// zero means CSV_DELIMITER
+	Delimiter rune
+	// the first row holds column names
+	Header bool
+}
+
+func (options CSVOptions) delimiter() rune {
+	if options.Delimiter == 0 {
+		return CSV_DELIMITER
+	}
+	return options.Delimiter
+}
+
+// reads a square matrix (or a single row / column vector), one matrix row per record
+type CSVMatrixReader struct {
+	file    *os.File
+	reader  *csv.Reader
+	options CSVOptions
+
+	// column names, if options.Header is set
+	Header []string
+	rows   [][]float64
+	// line in the file for every row
+	lines  []int
+	loaded bool
+}
+
+func NewCSVMatrixReader(filename string, options CSVOptions) (*CSVMatrixReader, error) {
+	matrixReader := CSVMatrixReader{options: options}
+	file, err := os.Open(filename)
+	if err != nil {
+		return &matrixReader, err
+	}
+
+	matrixReader.file = file
+	matrixReader.reader = csv.NewReader(file)
+	matrixReader.reader.Comma = options.delimiter()
+	matrixReader.reader.Comment = '#'
+	// the shape is validated by the reader itself to report positions
+	matrixReader.reader.FieldsPerRecord = -1
+	return &matrixReader, nil
+}
+
+func NewTSVMatrixReader(filename string, header bool) (*CSVMatrixReader, error) {
+	return NewCSVMatrixReader(filename, CSVOptions{Delimiter: TSV_DELIMITER, Header: header})
+}
+
+// reads and parses all records, errors contain row and column positions in the file
+func (reader *CSVMatrixReader) load() error {
+	if reader.loaded {
+		return nil
+	}
+	reader.loaded = true
+
+	for {
+		record, err := reader.reader.Read()
+		if err == goio.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		line, _ := reader.reader.FieldPos(0)
+		if reader.options.Header && reader.Header == nil {
+			reader.Header = record
+			continue
+		}
+
+		row := make([]float64, 0, len(record))
+		for j, field := range record {
+			value, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				line, _ := reader.reader.FieldPos(j)
+				return fmt.Errorf("row %v, column %v: %v", line, j+1, err)
+			}
+			row = append(row, value)
+		}
+		reader.rows = append(reader.rows, row)
+		reader.lines = append(reader.lines, line)
+	}
+
+	if reader.options.Header && reader.Header == nil {
+		return fmt.Errorf("header row is missing")
+	}
+	if len(reader.rows) == 0 {
+		return fmt.Errorf("no rows in the file")
+	}
+	return nil
+}
+
+// the number of records must be equal to the number of fields in every record
+func (reader *CSVMatrixReader) checkSquare() error {
+	size := len(reader.rows)
+	for i, row := range reader.rows {
+		if len(row) != size {
+			return fmt.Errorf("row %v: expected %v columns, got %v", reader.lines[i], size, len(row))
+		}
+	}
+	return nil
+}
+
+func (reader *CSVMatrixReader) isRow(size int) bool {
+	return len(reader.rows) == 1 && len(reader.rows[0]) == size
+}
+
+func (reader *CSVMatrixReader) isColumn(size int) bool {
+	if len(reader.rows) != size {
+		return false
+	}
+	for _, row := range reader.rows {
+		if len(row) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// dimension of the square matrix or the length of the vector
+func (reader *CSVMatrixReader) ReadDimension() (int, error) {
+	if err := reader.load(); err != nil {
+		return 0, err
+	}
+
+	if len(reader.rows) == 1 {
+		return len(reader.rows[0]), nil
+	}
+	if reader.isColumn(len(reader.rows)) {
+		return len(reader.rows), nil
+	}
+	if err := reader.checkSquare(); err != nil {
+		return 0, err
+	}
+	return len(reader.rows), nil
+}
+
+func (reader *CSVMatrixReader) ReadMatrix(size int) (*matrix.SquareMatrix, error) {
+	if err := reader.load(); err != nil {
+		return &matrix.SquareMatrix{}, err
+	}
+	if err := reader.checkSquare(); err != nil {
+		return &matrix.SquareMatrix{}, err
+	}
+	if len(reader.rows) != size {
+		return &matrix.SquareMatrix{}, fmt.Errorf("expected %v rows, got %v", size, len(reader.rows))
+	}
+	return matrix.NewSquareMatrix(reader.rows)
+}
+
+func (reader *CSVMatrixReader) readVector(size int) ([]float64, error) {
+	if err := reader.load(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case reader.isRow(size):
+		return reader.rows[0], nil
+	case reader.isColumn(size):
+		result := make([]float64, 0, size)
+		for _, row := range reader.rows {
+			result = append(result, row[0])
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("expected a single row or column of size %v", size)
+}
+
+func (reader *CSVMatrixReader) ReadRow(size int) (*matrix.Row, error) {
+	row, err := reader.readVector(size)
+	return matrix.NewRow(row), err
+}
+
+func (reader *CSVMatrixReader) ReadColumn(size int) (*matrix.Column, error) {
+	col, err := reader.readVector(size)
+	return matrix.NewColumn(col), err
+}
+
+func (reader *CSVMatrixReader) Close() error {
+	return reader.file.Close()
+}
+
+// writes matrices and vectors as records, the first error is kept and returned by Close
+type CSVMatrixWriter struct {
+	file    *os.File
+	writer  *csv.Writer
+	options CSVOptions
+
+	err error
+}
+
+func NewCSVMatrixWriter(filename string, options CSVOptions) (*CSVMatrixWriter, error) {
+	matrixWriter := CSVMatrixWriter{options: options}
+	file, err := os.Create(filename)
+	if err != nil {
+		return &matrixWriter, err
+	}
+
+	matrixWriter.file = file
+	matrixWriter.writer = csv.NewWriter(file)
+	matrixWriter.writer.Comma = options.delimiter()
+	return &matrixWriter, nil
+}
+
+// writes to any stream (e.g. os.Stdout), Close flushes, but doesn't close it
+func NewStreamCSVMatrixWriter(stream goio.Writer, options CSVOptions) *CSVMatrixWriter {
+	matrixWriter := CSVMatrixWriter{options: options, writer: csv.NewWriter(stream)}
+	matrixWriter.writer.Comma = options.delimiter()
+	return &matrixWriter
+}
+
+func NewTSVMatrixWriter(filename string, header bool) (*CSVMatrixWriter, error) {
+	return NewCSVMatrixWriter(filename, CSVOptions{Delimiter: TSV_DELIMITER, Header: header})
+}
+
+func formatCSVValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func (writer *CSVMatrixWriter) keepError(err error) {
+	if writer.err == nil {
+		writer.err = err
+	}
+}
+
+func (writer *CSVMatrixWriter) write(record []string) {
+	writer.keepError(writer.writer.Write(record))
+}
+
+// header with column indices, like pandas writes by default
+func (writer *CSVMatrixWriter) writeHeader(size int) {
+	if !writer.options.Header {
+		return
+	}
+	header := make([]string, 0, size)
+	for j := 0; j < size; j++ {
+		header = append(header, strconv.Itoa(j))
+	}
+	writer.write(header)
+}
+
+func (writer *CSVMatrixWriter) writeValues(values []float64) {
+	record := make([]string, 0, len(values))
+	for _, v := range values {
+		record = append(record, formatCSVValue(v))
+	}
+	writer.write(record)
+}
+
+func (writer *CSVMatrixWriter) WriteMatrix(squareMatrix *matrix.SquareMatrix) {
+	writer.writeHeader(len(squareMatrix.Data))
+	for _, row := range squareMatrix.Data {
+		writer.writeValues(row)
+	}
+}
+
+func (writer *CSVMatrixWriter) WriteRow(row *matrix.Row) {
+	writer.writeHeader(len(row.Data))
+	writer.writeValues(row.Data)
+}
+
+func (writer *CSVMatrixWriter) WriteColumn(column *matrix.Column) {
+	writer.writeHeader(1)
+	for _, v := range column.Data {
+		writer.writeValues([]float64{v})
+	}
+}
+
+// one record per eigenvalue: index, real and imaginary parts, then eigenvector components
+// (empty, if there is no vector for the eigenvalue, e.g. complex ones in SolveQR)
+func (writer *CSVMatrixWriter) WriteEigenTable(eigenvalues []complex128, eigenvectors [][]float64) {
+	size := 0
+	for _, vector := range eigenvectors {
+		if len(vector) > size {
+			size = len(vector)
+		}
+	}
+
+	if writer.options.Header {
+		header := []string{"index", "re", "im"}
+		for j := 0; j < size; j++ {
+			header = append(header, fmt.Sprintf("v%v", j))
+		}
+		writer.write(header)
+	}
+
+	for i, eigenvalue := range eigenvalues {
+		record := []string{strconv.Itoa(i), formatCSVValue(real(eigenvalue)), formatCSVValue(imag(eigenvalue))}
+		var vector []float64
+		if i < len(eigenvectors) {
+			vector = eigenvectors[i]
+		}
+		for j := 0; j < size; j++ {
+			if j < len(vector) {
+				record = append(record, formatCSVValue(vector[j]))
+			} else {
+				record = append(record, "")
+			}
+		}
+		writer.write(record)
+	}
+}
+
+// real eigenvalues (e.g. Jacobi method) with their eigenvectors
+func (writer *CSVMatrixWriter) WriteRealEigenTable(eigenvalues []float64, eigenvectors [][]float64) {
+	converted := make([]complex128, 0, len(eigenvalues))
+	for _, v := range eigenvalues {
+		converted = append(converted, complex(v, 0))
+	}
+	writer.WriteEigenTable(converted, eigenvectors)
+}
+
+func (writer *CSVMatrixWriter) Close() error {
+	if writer.writer == nil {
+		return writer.err
+	}
+	writer.writer.Flush()
+	writer.keepError(writer.writer.Error())
+	if writer.file != nil {
+		writer.keepError(writer.file.Close())
+	}
+	return writer.err
+}
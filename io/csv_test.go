@@ -0,0 +1,137 @@
+package io
+
+import (
+	"bytes"
+	"cma-lab-go/matrix"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	data := [][]float64{{1, -2.5, 0}, {1e-300, 3, 4}, {5, 6, 1.0 / 3}}
+	tests := []struct {
+		name    string
+		options CSVOptions
+	}{
+		{"csv", CSVOptions{}},
+		{"csv with header", CSVOptions{Header: true}},
+		{"tsv", CSVOptions{Delimiter: TSV_DELIMITER}},
+		{"tsv with header", CSVOptions{Delimiter: TSV_DELIMITER, Header: true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "matrix.csv")
+			writer, err := NewCSVMatrixWriter(filename, test.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			squareMatrix, _ := matrix.NewSquareMatrix(data)
+			writer.WriteMatrix(squareMatrix)
+			if err := writer.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			reader, err := NewCSVMatrixReader(filename, test.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+			size, err := reader.ReadDimension()
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := reader.ReadMatrix(size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual.Data, data) {
+				t.Errorf("got %v, expected %v", actual.Data, data)
+			}
+			if test.options.Header && !reflect.DeepEqual(reader.Header, []string{"0", "1", "2"}) {
+				t.Errorf("header is %v", reader.Header)
+			}
+		})
+	}
+}
+
+func TestCSVVector(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"row", "1, 2, 3\n"},
+		{"column", "# comment\n1\n2\n3\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader, err := NewCSVMatrixReader(writeTempFile(t, "vector.csv", []byte(test.content)), CSVOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+			size, err := reader.ReadDimension()
+			if err != nil {
+				t.Fatal(err)
+			}
+			row, err := reader.ReadRow(size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := []float64{1, 2, 3}; !reflect.DeepEqual(row.Data, expected) {
+				t.Errorf("got %v, expected %v", row.Data, expected)
+			}
+		})
+	}
+}
+
+func TestCSVErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		options CSVOptions
+		message string
+	}{
+		{"empty", "", CSVOptions{}, "no rows"},
+		{"header only", "a,b\n", CSVOptions{Header: true}, "no rows"},
+		{"missing header", "", CSVOptions{Header: true}, "header row is missing"},
+		{"bad number", "1,2\n3,x\n", CSVOptions{}, "row 2, column 2"},
+		{"not square", "1,2\n3,4\n5,6\n", CSVOptions{}, "expected 3 columns"},
+		{"short row", "1,2\n3\n", CSVOptions{}, "row 2: expected 2 columns"},
+		{"bad quote", "1,\"2\n", CSVOptions{}, "quote"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader, err := NewCSVMatrixReader(writeTempFile(t, "matrix.csv", []byte(test.content)), test.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+			size, err := reader.ReadDimension()
+			if err == nil {
+				_, err = reader.ReadMatrix(size)
+			}
+			if err == nil || !strings.Contains(err.Error(), test.message) {
+				t.Errorf("got error %v, expected %q", err, test.message)
+			}
+		})
+	}
+}
+
+func TestCSVEigenTable(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := NewStreamCSVMatrixWriter(&buffer, CSVOptions{Header: true})
+	writer.WriteEigenTable([]complex128{2, complex(1, -1)}, [][]float64{{1, 0.5}, nil})
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "index,re,im,v0,v1\n0,2,0,1,0.5\n1,1,-1,,\n"
+	if buffer.String() != expected {
+		t.Errorf("got %q, expected %q", buffer.String(), expected)
+	}
+}
@@ -0,0 +1,66 @@
+package io
+
+import (
+	"fmt"
+	goio "io"
+)
+
+type ParseErrorKind int
+
+const (
+	// the input ended cleanly before the next matrix or vector
+	EOF_ERROR ParseErrorKind = iota
+	// the input ended in the middle of the matrix or vector
+	TRUNCATED_ERROR
+	BAD_NUMBER_ERROR
+	BAD_DIMENSION_ERROR
+	NEGATIVE_DIMENSION_ERROR
+	HUGE_DIMENSION_ERROR
+	// error of the underlying reader
+	READ_ERROR
+)
+
+func (kind ParseErrorKind) String() string {
+	switch kind {
+	case EOF_ERROR:
+		return "end of input"
+	case TRUNCATED_ERROR:
+		return "truncated input"
+	case BAD_NUMBER_ERROR:
+		return "bad number"
+	case BAD_DIMENSION_ERROR:
+		return "bad dimension"
+	case NEGATIVE_DIMENSION_ERROR:
+		return "negative dimension"
+	case HUGE_DIMENSION_ERROR:
+		return "huge dimension"
+	case READ_ERROR:
+		return "read error"
+	}
+	return fmt.Sprintf("ParseErrorKind(%d)", int(kind))
+}
+
+// position is 1-based, it points to the token start (or to the end of input)
+type ParseError struct {
+	Kind   ParseErrorKind
+	Line   int
+	Column int
+	Token  string
+	// underlying error: goio.EOF, goio.ErrUnexpectedEOF, strconv errors and so on
+	Err error
+}
+
+func (err *ParseError) Error() string {
+	message := fmt.Sprintf("line %v, column %v: %v", err.Line, err.Column, err.Kind)
+	if err.Token != "" {
+		message += fmt.Sprintf(" %q", err.Token)
+	}
+	if err.Err != nil && err.Err != goio.EOF && err.Err != goio.ErrUnexpectedEOF {
+		message += fmt.Sprintf(": %v", err.Err)
+	}
+	return message
+}
+
+func (err *ParseError) Unwrap() error {
+	return err.Err
+}
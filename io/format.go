@@ -0,0 +1,154 @@
+package io
+
+import (
+	"cma-lab-go/matrix"
+	"fmt"
+	goio "io"
+	"strconv"
+	"strings"
+)
+
+type NumberFormat struct {
+	// digits after the point, negative means the shortest exact representation
+	Precision  int
+	Scientific bool
+	// pad numbers to the same width, so that columns are aligned (to the right)
+	Align bool
+}
+
+var DefaultNumberFormat = NumberFormat{Precision: -1, Align: true}
+
+func (format NumberFormat) Format(value float64) string {
+	verb := byte('g')
+	switch {
+	case format.Scientific:
+		verb = 'e'
+	case format.Precision >= 0:
+		verb = 'f'
+	}
+	return strconv.FormatFloat(value, verb, format.Precision, 64)
+}
+
+// "re", if the imaginary part is zero, otherwise "re+imi" (spaced around the sign, if asked)
+func (format NumberFormat) FormatComplex(value complex128, spaced bool) string {
+	if imag(value) == 0 {
+		return format.Format(real(value))
+	}
+
+	sign := "+"
+	if imag(value) < 0 {
+		sign = "-"
+	}
+	if spaced {
+		sign = " " + sign + " "
+	}
+	im := format.Format(imag(value))
+	return format.Format(real(value)) + sign + strings.TrimPrefix(im, "-") + "i"
+}
+
+// writers of eigenvalues with their eigenvectors (nil for complex eigenvalues, see SolveQR)
+type EigenTableWriter interface {
+	WriteEigenTable(eigenvalues []complex128, eigenvectors [][]float64)
+}
+
+// common part of the writers to io.Writer, the first error is kept and returned by Err
+type formattedWriter struct {
+	writer goio.Writer
+	format NumberFormat
+
+	err error
+}
+
+func (writer *formattedWriter) printf(format string, args ...interface{}) {
+	if writer.err != nil {
+		return
+	}
+	_, writer.err = fmt.Fprintf(writer.writer, format, args...)
+}
+
+func (writer *formattedWriter) formatCells(rows [][]float64) [][]string {
+	cells := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		line := make([]string, 0, len(row))
+		for _, v := range row {
+			line = append(line, writer.format.Format(v))
+		}
+		cells = append(cells, line)
+	}
+	return cells
+}
+
+// pads cells of every column to the same width
+func (writer *formattedWriter) align(cells [][]string) [][]string {
+	if !writer.format.Align {
+		return cells
+	}
+
+	var widths []int
+	for _, line := range cells {
+		for j, cell := range line {
+			if j == len(widths) {
+				widths = append(widths, 0)
+			}
+			if len(cell) > widths[j] {
+				widths[j] = len(cell)
+			}
+		}
+	}
+
+	result := make([][]string, 0, len(cells))
+	for _, line := range cells {
+		padded := make([]string, 0, len(line))
+		for j, cell := range line {
+			padded = append(padded, strings.Repeat(" ", widths[j]-len(cell))+cell)
+		}
+		result = append(result, padded)
+	}
+	return result
+}
+
+func (writer *formattedWriter) Err() error {
+	return writer.err
+}
+
+// plain text: numbers are separated by Delimiter, one matrix row per line
+type TextMatrixWriter struct {
+	formattedWriter
+	Delimiter string
+}
+
+func NewTextMatrixWriter(writer goio.Writer, format NumberFormat) *TextMatrixWriter {
+	return &TextMatrixWriter{formattedWriter: formattedWriter{writer: writer, format: format}, Delimiter: " "}
+}
+
+func (writer *TextMatrixWriter) writeLines(cells [][]string) {
+	for _, line := range writer.align(cells) {
+		writer.printf("%v\n", strings.Join(line, writer.Delimiter))
+	}
+}
+
+func (writer *TextMatrixWriter) WriteMatrix(squareMatrix *matrix.SquareMatrix) {
+	writer.writeLines(writer.formatCells(squareMatrix.Data))
+}
+
+func (writer *TextMatrixWriter) WriteRow(row *matrix.Row) {
+	writer.writeLines(writer.formatCells([][]float64{row.Data}))
+}
+
+func (writer *TextMatrixWriter) WriteColumn(column *matrix.Column) {
+	rows := make([][]float64, 0, len(column.Data))
+	for _, v := range column.Data {
+		rows = append(rows, []float64{v})
+	}
+	writer.writeLines(writer.formatCells(rows))
+}
+
+func (writer *TextMatrixWriter) WriteEigenTable(eigenvalues []complex128, eigenvectors [][]float64) {
+	for i, eigenvalue := range eigenvalues {
+		writer.printf("Eigenvalue = %v\n", writer.format.FormatComplex(eigenvalue, false))
+		if i < len(eigenvectors) && eigenvectors[i] != nil {
+			cells := writer.formatCells([][]float64{eigenvectors[i]})[0]
+			writer.printf("Eigenvector = %v\n", strings.Join(cells, writer.Delimiter))
+		}
+	}
+}
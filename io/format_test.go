@@ -0,0 +1,129 @@
+package io
+
+import (
+	"bytes"
+	"cma-lab-go/matrix"
+	"errors"
+	"testing"
+)
+
+// writer, which fails after the given number of writes
+type failingWriter struct {
+	writes int
+}
+
+var errWriteFailed = errors.New("write failed")
+
+func (writer *failingWriter) Write(data []byte) (int, error) {
+	if writer.writes == 0 {
+		return 0, errWriteFailed
+	}
+	writer.writes--
+	return len(data), nil
+}
+
+func writeSamples(writer interface {
+	MatrixWriter
+	EigenTableWriter
+}) {
+	squareMatrix, _ := matrix.NewSquareMatrix([][]float64{{1, -2.5}, {10, 0}})
+	writer.WriteMatrix(squareMatrix)
+	writer.WriteColumn(matrix.NewColumn([]float64{1, 20}))
+	writer.WriteEigenTable([]complex128{2, complex(1, -1)}, [][]float64{{1, 0.5}, nil})
+}
+
+func TestTextMatrixWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   NumberFormat
+		expected string
+	}{
+		{"default", DefaultNumberFormat,
+			" 1 -2.5\n10    0\n 1\n20\nEigenvalue = 2\nEigenvector = 1 0.5\nEigenvalue = 1-1i\n"},
+		{"fixed", NumberFormat{Precision: 2},
+			"1.00 -2.50\n10.00 0.00\n1.00\n20.00\nEigenvalue = 2.00\nEigenvector = 1.00 0.50\nEigenvalue = 1.00-1.00i\n"},
+		{"scientific", NumberFormat{Precision: 1, Scientific: true, Align: true},
+			"1.0e+00 -2.5e+00\n1.0e+01  0.0e+00\n1.0e+00\n2.0e+01\nEigenvalue = 2.0e+00\nEigenvector = 1.0e+00 5.0e-01\nEigenvalue = 1.0e+00-1.0e+00i\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			writer := NewTextMatrixWriter(&buffer, test.format)
+			writeSamples(writer)
+			if err := writer.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if buffer.String() != test.expected {
+				t.Errorf("got %q, expected %q", buffer.String(), test.expected)
+			}
+		})
+	}
+}
+
+func TestLatexMatrixWriter(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := NewLatexMatrixWriter(&buffer, DefaultNumberFormat)
+	writeSamples(writer)
+	if err := writer.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "\\begin{bmatrix}\n   1 & -2.5 \\\\\n  10 &    0\n\\end{bmatrix}\n" +
+		"\\begin{bmatrix}\n   1 \\\\\n  20\n\\end{bmatrix}\n" +
+		"\\begin{align*}\n\\lambda_{1} &= 2, & x_{1} &= \\begin{bmatrix}\n    1 \\\\\n  0.5\n\\end{bmatrix} \\\\\n" +
+		"\\lambda_{2} &= 1 - 1i\n\\end{align*}\n"
+	if buffer.String() != expected {
+		t.Errorf("got %q, expected %q", buffer.String(), expected)
+	}
+}
+
+func TestMarkdownMatrixWriter(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := NewMarkdownMatrixWriter(&buffer, DefaultNumberFormat)
+	writeSamples(writer)
+	if err := writer.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "|   1 |    2 |\n| --: | ---: |\n|   1 | -2.5 |\n|  10 |    0 |\n\n" +
+		"|   i | value |\n| --: | ----: |\n|   1 |     1 |\n|   2 |    20 |\n\n" +
+		"|   # | eigenvalue | x_1 | x_2 |\n| --: | ---------: | --: | --: |\n" +
+		"|   1 |          2 |   1 | 0.5 |\n|   2 |       1-1i |     |     |\n\n"
+	if buffer.String() != expected {
+		t.Errorf("got %q, expected %q", buffer.String(), expected)
+	}
+}
+
+func TestFormattedWriterErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		writer func(output *failingWriter) interface{ Err() error }
+	}{
+		{"text", func(output *failingWriter) interface{ Err() error } {
+			writer := NewTextMatrixWriter(output, DefaultNumberFormat)
+			writeSamples(writer)
+			return writer
+		}},
+		{"latex", func(output *failingWriter) interface{ Err() error } {
+			writer := NewLatexMatrixWriter(output, DefaultNumberFormat)
+			writeSamples(writer)
+			return writer
+		}},
+		{"markdown", func(output *failingWriter) interface{ Err() error } {
+			writer := NewMarkdownMatrixWriter(output, DefaultNumberFormat)
+			writeSamples(writer)
+			return writer
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// the first error is kept and returned by Err
+			output := &failingWriter{writes: 1}
+			if err := test.writer(output).Err(); !errors.Is(err, errWriteFailed) {
+				t.Errorf("got error %v, expected %v", err, errWriteFailed)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package io
+
+import (
+	"cma-lab-go/matrix"
+	"encoding/json"
+	"fmt"
+	goio "io"
+	"os"
+)
+
+// reads a stream of JSON values: matrices as arrays of rows, vectors as plain arrays
+// ReadDimension decodes the next value, the following Read* call returns it
+type JSONMatrixReader struct {
+	file    *os.File
+	decoder *json.Decoder
+
+	pending json.RawMessage
+}
+
+func NewJSONMatrixReader(filename string) (*JSONMatrixReader, error) {
+	matrixReader := JSONMatrixReader{}
+	file, err := os.Open(filename)
+	if err != nil {
+		return &matrixReader, err
+	}
+
+	matrixReader.file = file
+	matrixReader.decoder = json.NewDecoder(file)
+	return &matrixReader, nil
+}
+
+func (reader *JSONMatrixReader) next() (json.RawMessage, error) {
+	if reader.pending != nil {
+		value := reader.pending
+		reader.pending = nil
+		return value, nil
+	}
+
+	var value json.RawMessage
+	if err := reader.decoder.Decode(&value); err != nil {
+		if err == goio.EOF {
+			return nil, fmt.Errorf("no more values: %w", err)
+		}
+		return nil, err
+	}
+	return value, nil
+}
+
+// dimension of the next matrix or the length of the next vector
+func (reader *JSONMatrixReader) ReadDimension() (int, error) {
+	value, err := reader.next()
+	if err != nil {
+		return 0, err
+	}
+
+	var elements []json.RawMessage
+	if err := json.Unmarshal(value, &elements); err != nil {
+		return 0, err
+	}
+	reader.pending = value
+	return len(elements), nil
+}
+
+func (reader *JSONMatrixReader) ReadMatrix(size int) (*matrix.SquareMatrix, error) {
+	value, err := reader.next()
+	if err != nil {
+		return &matrix.SquareMatrix{}, err
+	}
+
+	var result matrix.SquareMatrix
+	if err := json.Unmarshal(value, &result); err != nil {
+		return &matrix.SquareMatrix{}, err
+	}
+	if len(result.Data) != size {
+		return &matrix.SquareMatrix{}, fmt.Errorf("expected %v x %v matrix, got %v x %v",
+			size, size, len(result.Data), len(result.Data))
+	}
+	return &result, nil
+}
+
+func (reader *JSONMatrixReader) readVector(size int) ([]float64, error) {
+	value, err := reader.next()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []float64
+	if err := json.Unmarshal(value, &result); err != nil {
+		return nil, err
+	}
+	if len(result) != size {
+		return nil, fmt.Errorf("expected vector of size %v, got %v", size, len(result))
+	}
+	return result, nil
+}
+
+func (reader *JSONMatrixReader) ReadRow(size int) (*matrix.Row, error) {
+	row, err := reader.readVector(size)
+	return matrix.NewRow(row), err
+}
+
+func (reader *JSONMatrixReader) ReadColumn(size int) (*matrix.Column, error) {
+	col, err := reader.readVector(size)
+	return matrix.NewColumn(col), err
+}
+
+// decodes the next value into any type (e.g. solver results from cma_methods)
+func (reader *JSONMatrixReader) ReadValue(value interface{}) error {
+	data, err := reader.next()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+
+func (reader *JSONMatrixReader) Close() error {
+	return reader.file.Close()
+}
+
+// writes one JSON value per line, the first error is kept and returned by Err
+type JSONMatrixWriter struct {
+	encoder *json.Encoder
+
+	err error
+}
+
+func NewJSONMatrixWriter(writer goio.Writer) *JSONMatrixWriter {
+	return &JSONMatrixWriter{encoder: json.NewEncoder(writer)}
+}
+
+// writes to the standard output, like ConsoleMatrixWriter does
+func NewConsoleJSONMatrixWriter() *JSONMatrixWriter {
+	return NewJSONMatrixWriter(os.Stdout)
+}
+
+// writes any marshallable value (e.g. solver results from cma_methods)
+func (writer *JSONMatrixWriter) WriteValue(value interface{}) {
+	if err := writer.encoder.Encode(value); err != nil && writer.err == nil {
+		writer.err = err
+	}
+}
+
+func (writer *JSONMatrixWriter) WriteMatrix(squareMatrix *matrix.SquareMatrix) {
+	writer.WriteValue(squareMatrix)
+}
+
+func (writer *JSONMatrixWriter) WriteRow(row *matrix.Row) {
+	writer.WriteValue(row)
+}
+
+func (writer *JSONMatrixWriter) WriteColumn(column *matrix.Column) {
+	writer.WriteValue(column)
+}
+
+func (writer *JSONMatrixWriter) Err() error {
+	return writer.err
+}
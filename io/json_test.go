@@ -0,0 +1,96 @@
+package io
+
+import (
+	"bytes"
+	"cma-lab-go/matrix"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	data := [][]float64{{1, -2.5}, {1e-300, 1.0 / 3}}
+	vector := []float64{1, 2, 3}
+
+	var buffer bytes.Buffer
+	writer := NewJSONMatrixWriter(&buffer)
+	squareMatrix, _ := matrix.NewSquareMatrix(data)
+	writer.WriteMatrix(squareMatrix)
+	writer.WriteRow(matrix.NewRow(vector))
+	writer.WriteColumn(matrix.NewColumn(vector))
+	if err := writer.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewJSONMatrixReader(writeTempFile(t, "matrices.json", buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	size, err := reader.ReadDimension()
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := reader.ReadMatrix(size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(actual.Data, data) {
+		t.Errorf("got %v, expected %v", actual.Data, data)
+	}
+
+	if size, err = reader.ReadDimension(); err != nil {
+		t.Fatal(err)
+	}
+	row, err := reader.ReadRow(size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(row.Data, vector) {
+		t.Errorf("got %v, expected %v", row.Data, vector)
+	}
+
+	column, err := reader.ReadColumn(len(vector))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(column.Data, vector) {
+		t.Errorf("got %v, expected %v", column.Data, vector)
+	}
+
+	if _, err := reader.ReadDimension(); err == nil || !strings.Contains(err.Error(), "no more values") {
+		t.Errorf("got error %v after the last value", err)
+	}
+}
+
+func TestJSONErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{"empty", "", "no more values"},
+		{"not an array", "{\"a\": 1}", "cannot unmarshal"},
+		{"truncated", "[[1, 2], [3", "unexpected EOF"},
+		{"not square", "[[1, 2], [3]]", "row 1: expected 2 elements"},
+		{"bad number", "[[1, \"x\"], [3, 4]]", "cannot unmarshal"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader, err := NewJSONMatrixReader(writeTempFile(t, "matrix.json", []byte(test.content)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+			size, err := reader.ReadDimension()
+			if err == nil {
+				_, err = reader.ReadMatrix(size)
+			}
+			if err == nil || !strings.Contains(err.Error(), test.message) {
+				t.Errorf("got error %v, expected %q", err, test.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package io
+
+import (
+	"cma-lab-go/matrix"
+	goio "io"
+	"strconv"
+	"strings"
+)
+
+// LaTeX bmatrix environments (amsmath), one per matrix or vector
+type LatexMatrixWriter struct {
+	formattedWriter
+}
+
+func NewLatexMatrixWriter(writer goio.Writer, format NumberFormat) *LatexMatrixWriter {
+	return &LatexMatrixWriter{formattedWriter: formattedWriter{writer: writer, format: format}}
+}
+
+func (writer *LatexMatrixWriter) bmatrix(cells [][]string) string {
+	lines := make([]string, 0, len(cells))
+	for _, line := range writer.align(cells) {
+		lines = append(lines, "  "+strings.Join(line, " & "))
+	}
+	return "\\begin{bmatrix}\n" + strings.Join(lines, " \\\\\n") + "\n\\end{bmatrix}"
+}
+
+func (writer *LatexMatrixWriter) column(values []float64) string {
+	rows := make([][]float64, 0, len(values))
+	for _, v := range values {
+		rows = append(rows, []float64{v})
+	}
+	return writer.bmatrix(writer.formatCells(rows))
+}
+
+func (writer *LatexMatrixWriter) WriteMatrix(squareMatrix *matrix.SquareMatrix) {
+	writer.printf("%v\n", writer.bmatrix(writer.formatCells(squareMatrix.Data)))
+}
+
+func (writer *LatexMatrixWriter) WriteRow(row *matrix.Row) {
+	writer.printf("%v\n", writer.bmatrix(writer.formatCells([][]float64{row.Data})))
+}
+
+func (writer *LatexMatrixWriter) WriteColumn(column *matrix.Column) {
+	writer.printf("%v\n", writer.column(column.Data))
+}
+
+// align* environment with a line per eigenvalue: \lambda_i = ..., x_i = bmatrix
+func (writer *LatexMatrixWriter) WriteEigenTable(eigenvalues []complex128, eigenvectors [][]float64) {
+	lines := make([]string, 0, len(eigenvalues))
+	for i, eigenvalue := range eigenvalues {
+		line := "\\lambda_{" + strconv.Itoa(i+1) + "} &= " + writer.format.FormatComplex(eigenvalue, true)
+		if i < len(eigenvectors) && eigenvectors[i] != nil {
+			line += ", & x_{" + strconv.Itoa(i+1) + "} &= " + writer.column(eigenvectors[i])
+		}
+		lines = append(lines, line)
+	}
+	writer.printf("\\begin{align*}\n%v\n\\end{align*}\n", strings.Join(lines, " \\\\\n"))
+}
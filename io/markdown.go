@@ -0,0 +1,99 @@
+package io
+
+import (
+	"cma-lab-go/matrix"
+	goio "io"
+	"strconv"
+	"strings"
+)
+
+// Markdown tables, columns are numbered from 1 in the header
+type MarkdownMatrixWriter struct {
+	formattedWriter
+}
+
+func NewMarkdownMatrixWriter(writer goio.Writer, format NumberFormat) *MarkdownMatrixWriter {
+	return &MarkdownMatrixWriter{formattedWriter: formattedWriter{writer: writer, format: format}}
+}
+
+// the header is aligned together with the cells, numbers are aligned to the right
+func (writer *MarkdownMatrixWriter) table(header []string, cells [][]string) {
+	// separators are at least 3 characters long
+	padded := make([]string, 0, len(header))
+	for _, cell := range header {
+		if len(cell) < 3 {
+			cell = strings.Repeat(" ", 3-len(cell)) + cell
+		}
+		padded = append(padded, cell)
+	}
+	aligned := writer.align(append([][]string{padded}, cells...))
+
+	separator := make([]string, 0, len(header))
+	for _, cell := range aligned[0] {
+		width := len(cell)
+		if width < 3 {
+			width = 3
+		}
+		separator = append(separator, strings.Repeat("-", width-1)+":")
+	}
+
+	writer.printf("| %v |\n", strings.Join(aligned[0], " | "))
+	writer.printf("| %v |\n", strings.Join(separator, " | "))
+	for _, line := range aligned[1:] {
+		writer.printf("| %v |\n", strings.Join(line, " | "))
+	}
+	writer.printf("\n")
+}
+
+func indexHeader(size int) []string {
+	header := make([]string, 0, size)
+	for j := 1; j <= size; j++ {
+		header = append(header, strconv.Itoa(j))
+	}
+	return header
+}
+
+func (writer *MarkdownMatrixWriter) WriteMatrix(squareMatrix *matrix.SquareMatrix) {
+	writer.table(indexHeader(len(squareMatrix.Data)), writer.formatCells(squareMatrix.Data))
+}
+
+func (writer *MarkdownMatrixWriter) WriteRow(row *matrix.Row) {
+	writer.table(indexHeader(len(row.Data)), writer.formatCells([][]float64{row.Data}))
+}
+
+func (writer *MarkdownMatrixWriter) WriteColumn(column *matrix.Column) {
+	cells := make([][]string, 0, len(column.Data))
+	for i, v := range column.Data {
+		cells = append(cells, []string{strconv.Itoa(i + 1), writer.format.Format(v)})
+	}
+	writer.table([]string{"i", "value"}, cells)
+}
+
+// a row per eigenvalue: index, eigenvalue and eigenvector components x_1, ..., x_n
+func (writer *MarkdownMatrixWriter) WriteEigenTable(eigenvalues []complex128, eigenvectors [][]float64) {
+	size := 0
+	for _, vector := range eigenvectors {
+		if len(vector) > size {
+			size = len(vector)
+		}
+	}
+
+	header := []string{"#", "eigenvalue"}
+	for j := 1; j <= size; j++ {
+		header = append(header, "x_"+strconv.Itoa(j))
+	}
+
+	cells := make([][]string, 0, len(eigenvalues))
+	for i, eigenvalue := range eigenvalues {
+		line := []string{strconv.Itoa(i + 1), writer.format.FormatComplex(eigenvalue, false)}
+		for j := 0; j < size; j++ {
+			if i < len(eigenvectors) && j < len(eigenvectors[i]) {
+				line = append(line, writer.format.Format(eigenvectors[i][j]))
+			} else {
+				line = append(line, "")
+			}
+		}
+		cells = append(cells, line)
+	}
+	writer.table(header, cells)
+}
@@ -0,0 +1,230 @@
+package io
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	goio "io"
+	"math"
+	"os"
+)
+
+// MATLAB Level 4 .mat: every variable is a header of 5 int32 (type, rows, columns, imaginary flag,
+// name length with the trailing zero), the name, then real and imaginary parts by columns
+// type = 1000 * M + 100 * O + 10 * P + T, M is byte order (0 little, 1 big endian),
+// P is precision (0 float64, 1 float32, 2 int32, 3 int16, 4 uint16, 5 uint8), T is 0 for full numeric matrices
+const MAT_HEADER_SIZE = 20
+
+// the maximum type value, bigger ones mean the other byte order
+const MAT_MAX_TYPE = 4052
+
+type MatMatrixReader struct {
+	variableMatrixReader
+	file   *os.File
+	reader *bufio.Reader
+}
+
+func NewMatMatrixReader(filename string) (*MatMatrixReader, error) {
+	matrixReader := &MatMatrixReader{}
+	file, err := os.Open(filename)
+	if err != nil {
+		return matrixReader, err
+	}
+
+	matrixReader.file = file
+	matrixReader.reader = bufio.NewReader(file)
+	matrixReader.readVariable = matrixReader.ReadVariable
+	return matrixReader, nil
+}
+
+func matPrecisionSize(precision int) int {
+	switch precision {
+	case 0:
+		return 8
+	case 1, 2:
+		return 4
+	case 3, 4:
+		return 2
+	case 5:
+		return 1
+	}
+	return 0
+}
+
+func decodeMatValue(order binary.ByteOrder, precision int, data []byte) float64 {
+	switch precision {
+	case 0:
+		return math.Float64frombits(order.Uint64(data))
+	case 1:
+		return float64(math.Float32frombits(order.Uint32(data)))
+	case 2:
+		return float64(int32(order.Uint32(data)))
+	case 3:
+		return float64(int16(order.Uint16(data)))
+	case 4:
+		return float64(order.Uint16(data))
+	}
+	return float64(data[0])
+}
+
+// column-major part of the variable into row-major slice
+func (reader *MatMatrixReader) readPart(variable *Variable, order binary.ByteOrder, precision int) ([]float64, error) {
+	size := matPrecisionSize(precision)
+	// the buffer grows with the data read, a short file doesn't allocate the whole variable
+	dataSize := variable.Rows * variable.Cols * size
+	data, err := goio.ReadAll(goio.LimitReader(reader.reader, int64(dataSize)))
+	if err != nil {
+		return nil, fmt.Errorf("variable %v: %w", variable.Name, err)
+	}
+	if len(data) < dataSize {
+		return nil, fmt.Errorf("variable %v: %w", variable.Name, goio.ErrUnexpectedEOF)
+	}
+
+	result := make([]float64, variable.Rows*variable.Cols)
+	k := 0
+	for j := 0; j < variable.Cols; j++ {
+		for i := 0; i < variable.Rows; i++ {
+			result[i*variable.Cols+j] = decodeMatValue(order, precision, data[k*size:])
+			k++
+		}
+	}
+	return result, nil
+}
+
+// next variable of the file, io.EOF if there are no more variables
+func (reader *MatMatrixReader) ReadVariable() (*Variable, error) {
+	header := make([]byte, MAT_HEADER_SIZE)
+	if _, err := goio.ReadFull(reader.reader, header); err != nil {
+		if err == goio.EOF {
+			return nil, goio.EOF
+		}
+		return nil, fmt.Errorf("mat header: %w", err)
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	if kind := order.Uint32(header); kind > MAT_MAX_TYPE {
+		order = binary.BigEndian
+	}
+
+	kind := int(order.Uint32(header))
+	rows := int(int32(order.Uint32(header[4:])))
+	cols := int(int32(order.Uint32(header[8:])))
+	imaginary := order.Uint32(header[12:]) != 0
+	nameLength := int(int32(order.Uint32(header[16:])))
+
+	machine, other, precision, text := kind/1000, kind/100%10, kind/10%10, kind%10
+	switch {
+	case kind < 0 || kind > MAT_MAX_TYPE || machine > 1 || other != 0:
+		return nil, fmt.Errorf("not a level 4 mat file (type %v)", kind)
+	case (machine == 1) != (order == binary.BigEndian):
+		return nil, fmt.Errorf("unsupported byte order in type %v", kind)
+	case matPrecisionSize(precision) == 0:
+		return nil, fmt.Errorf("unsupported precision in type %v", kind)
+	case text != 0:
+		return nil, fmt.Errorf("only full numeric matrices are supported, got type %v", kind)
+	case rows < 0 || cols < 0 || rows > MAX_DIMENSION || cols > MAX_DIMENSION:
+		return nil, fmt.Errorf("bad dimension %v x %v", rows, cols)
+	case nameLength < 1 || nameLength > 1<<16:
+		return nil, fmt.Errorf("bad name length %v", nameLength)
+	}
+
+	name := make([]byte, nameLength)
+	if _, err := goio.ReadFull(reader.reader, name); err != nil {
+		return nil, fmt.Errorf("mat variable name: %w", err)
+	}
+
+	variable := &Variable{Name: string(name[:nameLength-1]), Rows: rows, Cols: cols}
+	var err error
+	if variable.Data, err = reader.readPart(variable, order, precision); err != nil {
+		return nil, err
+	}
+	if imaginary {
+		if variable.Imag, err = reader.readPart(variable, order, precision); err != nil {
+			return nil, err
+		}
+	}
+	return variable, nil
+}
+
+func (reader *MatMatrixReader) Close() error {
+	return reader.file.Close()
+}
+
+// writes little-endian float64 variables, the first error is kept and returned by Close
+type MatMatrixWriter struct {
+	variableMatrixWriter
+	file   *os.File
+	writer *bufio.Writer
+
+	err error
+}
+
+func NewMatMatrixWriter(filename string) (*MatMatrixWriter, error) {
+	matrixWriter := &MatMatrixWriter{}
+	file, err := os.Create(filename)
+	if err != nil {
+		return matrixWriter, err
+	}
+
+	matrixWriter.file = file
+	matrixWriter.writer = bufio.NewWriter(file)
+	matrixWriter.writeVariable = matrixWriter.WriteVariable
+	return matrixWriter, nil
+}
+
+func (writer *MatMatrixWriter) keepError(err error) {
+	if writer.err == nil {
+		writer.err = err
+	}
+}
+
+func (writer *MatMatrixWriter) writePart(variable *Variable, values []float64) {
+	data := make([]byte, 8)
+	for j := 0; j < variable.Cols; j++ {
+		for i := 0; i < variable.Rows; i++ {
+			binary.LittleEndian.PutUint64(data, math.Float64bits(values[i*variable.Cols+j]))
+			if _, err := writer.writer.Write(data); err != nil {
+				writer.keepError(err)
+				return
+			}
+		}
+	}
+}
+
+func (writer *MatMatrixWriter) WriteVariable(variable *Variable) {
+	if writer.err != nil {
+		return
+	}
+
+	var imaginary uint32 = 0
+	if variable.IsComplex() {
+		imaginary = 1
+	}
+	header := make([]byte, MAT_HEADER_SIZE)
+	binary.LittleEndian.PutUint32(header[4:], uint32(variable.Rows))
+	binary.LittleEndian.PutUint32(header[8:], uint32(variable.Cols))
+	binary.LittleEndian.PutUint32(header[12:], imaginary)
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(variable.Name)+1))
+	_, err := writer.writer.Write(header)
+	writer.keepError(err)
+	_, err = writer.writer.WriteString(variable.Name)
+	writer.keepError(err)
+	writer.keepError(writer.writer.WriteByte(0))
+	if writer.err != nil {
+		return
+	}
+
+	writer.writePart(variable, variable.Data)
+	if variable.IsComplex() {
+		writer.writePart(variable, variable.Imag)
+	}
+}
+
+func (writer *MatMatrixWriter) Close() error {
+	if writer.file == nil {
+		return writer.err
+	}
+	writer.keepError(writer.writer.Flush())
+	writer.keepError(writer.file.Close())
+	return writer.err
+}
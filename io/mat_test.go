@@ -0,0 +1,88 @@
+package io
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// level 4 variable with the given header values, the name and raw data
+func matVariable(order binary.ByteOrder, kind, rows, cols, imaginary int32, name string, data []byte) []byte {
+	var buffer bytes.Buffer
+	binary.Write(&buffer, order, []int32{kind, rows, cols, imaginary, int32(len(name) + 1)})
+	buffer.WriteString(name)
+	buffer.WriteByte(0)
+	buffer.Write(data)
+	return buffer.Bytes()
+}
+
+func TestMatPrecisions(t *testing.T) {
+	// 2 x 2 matrix [1 3; 2 -4] by columns
+	float32Data := new(bytes.Buffer)
+	binary.Write(float32Data, binary.BigEndian, []float32{1, 2, 3, -4})
+	int16Data := new(bytes.Buffer)
+	binary.Write(int16Data, binary.LittleEndian, []int16{1, 2, 3, -4})
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{"big endian float32", matVariable(binary.BigEndian, 1010, 2, 2, 0, "A", float32Data.Bytes())},
+		{"little endian int16", matVariable(binary.LittleEndian, 30, 2, 2, 0, "A", int16Data.Bytes())},
+		{"uint8", matVariable(binary.LittleEndian, 50, 2, 2, 0, "A", []byte{1, 2, 3, 252})},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader, err := NewMatMatrixReader(writeTempFile(t, "matrix.mat", test.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+			variable, err := reader.ReadVariable()
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := []float64{1, 3, 2, -4}
+			if test.name == "uint8" {
+				expected[3] = 252
+			}
+			checkVariable(t, variable, &Variable{Name: "A", Rows: 2, Cols: 2, Data: expected})
+		})
+	}
+}
+
+func TestMatErrors(t *testing.T) {
+	order := binary.LittleEndian
+	tests := []struct {
+		name    string
+		content []byte
+		message string
+	}{
+		{"truncated header", make([]byte, MAT_HEADER_SIZE-1), "mat header"},
+		{"level 5", matVariable(order, 3000, 1, 1, 0, "A", make([]byte, 8)), "not a level 4 mat file"},
+		{"wrong byte order", matVariable(order, 1000, 1, 1, 0, "A", make([]byte, 8)), "unsupported byte order"},
+		{"unsupported precision", matVariable(order, 60, 1, 1, 0, "A", make([]byte, 8)), "unsupported precision"},
+		{"text matrix", matVariable(order, 1, 1, 1, 0, "A", make([]byte, 8)), "only full numeric matrices"},
+		{"negative dimension", matVariable(order, 0, -1, 1, 0, "A", nil), "bad dimension"},
+		{"huge dimension", matVariable(order, 0, 1<<20, 1, 0, "A", nil), "bad dimension"},
+		{"bad name length", append(matVariable(order, 0, 1, 1, 0, "", nil)[:16], 0, 0, 0, 0), "bad name length"},
+		{"truncated name", matVariable(order, 0, 1, 1, 0, "Name", nil)[:MAT_HEADER_SIZE+2], "mat variable name"},
+		{"truncated data", matVariable(order, 0, 2, 2, 0, "A", make([]byte, 31)), "variable A: unexpected EOF"},
+		{"huge data", matVariable(order, 0, MAX_DIMENSION, MAX_DIMENSION, 0, "A", nil), "variable A: unexpected EOF"},
+		{"missing imaginary part", matVariable(order, 0, 1, 1, 1, "A", make([]byte, 8)), "variable A: unexpected EOF"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader, err := NewMatMatrixReader(writeTempFile(t, "matrix.mat", test.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+			if _, err := reader.ReadVariable(); err == nil || !strings.Contains(err.Error(), test.message) {
+				t.Errorf("got error %v, expected %q", err, test.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,550 @@
+package io
+
+import (
+	"bufio"
+	"cma-lab-go/matrix"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const MATRIX_MARKET_BANNER = "%%MatrixMarket"
+
+type MatrixMarketFormat string
+type MatrixMarketField string
+type MatrixMarketSymmetry string
+
+const (
+	MATRIX_MARKET_COORDINATE MatrixMarketFormat = "coordinate"
+	MATRIX_MARKET_ARRAY      MatrixMarketFormat = "array"
+)
+
+const (
+	MATRIX_MARKET_REAL    MatrixMarketField = "real"
+	MATRIX_MARKET_INTEGER MatrixMarketField = "integer"
+	MATRIX_MARKET_PATTERN MatrixMarketField = "pattern"
+)
+
+const (
+	MATRIX_MARKET_GENERAL        MatrixMarketSymmetry = "general"
+	MATRIX_MARKET_SYMMETRIC      MatrixMarketSymmetry = "symmetric"
+	MATRIX_MARKET_SKEW_SYMMETRIC MatrixMarketSymmetry = "skew-symmetric"
+)
+
+type MatrixMarketHeader struct {
+	Format   MatrixMarketFormat
+	Field    MatrixMarketField
+	Symmetry MatrixMarketSymmetry
+}
+
+func (header *MatrixMarketHeader) validate() error {
+	switch header.Format {
+	case MATRIX_MARKET_COORDINATE, MATRIX_MARKET_ARRAY:
+	default:
+		return fmt.Errorf("unsupported format %q", header.Format)
+	}
+
+	switch header.Field {
+	case MATRIX_MARKET_REAL, MATRIX_MARKET_INTEGER:
+	case MATRIX_MARKET_PATTERN:
+		if header.Format != MATRIX_MARKET_COORDINATE {
+			return fmt.Errorf("pattern field requires coordinate format")
+		}
+		if header.Symmetry == MATRIX_MARKET_SKEW_SYMMETRIC {
+			return fmt.Errorf("pattern field can't be skew-symmetric")
+		}
+	default:
+		return fmt.Errorf("unsupported field %q", header.Field)
+	}
+
+	switch header.Symmetry {
+	case MATRIX_MARKET_GENERAL, MATRIX_MARKET_SYMMETRIC, MATRIX_MARKET_SKEW_SYMMETRIC:
+	default:
+		return fmt.Errorf("unsupported symmetry %q", header.Symmetry)
+	}
+	return nil
+}
+
+func (header *MatrixMarketHeader) String() string {
+	return fmt.Sprintf("%v matrix %v %v %v", MATRIX_MARKET_BANNER, header.Format, header.Field, header.Symmetry)
+}
+
+// reads a single matrix (or vector, if one of dimensions is 1) from the Matrix Market file
+type MatrixMarketReader struct {
+	file    *os.File
+	scanner *bufio.Scanner
+	line    int
+
+	header     *MatrixMarketHeader
+	rows, cols int
+	entries    int
+	consumed   bool
+}
+
+func NewMatrixMarketReader(filename string) (*MatrixMarketReader, error) {
+	matrixReader := MatrixMarketReader{}
+	file, err := os.Open(filename)
+	if err != nil {
+		return &matrixReader, err
+	}
+
+	matrixReader.file = file
+	matrixReader.scanner = bufio.NewScanner(file)
+	return &matrixReader, nil
+}
+
+// next line, which is neither a comment nor blank
+func (reader *MatrixMarketReader) nextLine() ([]string, error) {
+	for reader.scanner.Scan() {
+		reader.line++
+		text := strings.TrimSpace(reader.scanner.Text())
+		if text == "" || strings.HasPrefix(text, "%") {
+			continue
+		}
+		return strings.Fields(text), nil
+	}
+	if err := reader.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("line %v: unexpected end of file", reader.line)
+}
+
+func (reader *MatrixMarketReader) parseInt(token string) (int, error) {
+	value, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, fmt.Errorf("line %v: %v", reader.line, err)
+	}
+	return value, nil
+}
+
+func (reader *MatrixMarketReader) parseValue(token string) (float64, error) {
+	if reader.header.Field == MATRIX_MARKET_INTEGER {
+		value, err := strconv.ParseInt(token, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("line %v: %v", reader.line, err)
+		}
+		return float64(value), nil
+	}
+
+	value, err := strconv.ParseFloat(token, 64)
+	if err != nil {
+		return 0, fmt.Errorf("line %v: %v", reader.line, err)
+	}
+	return value, nil
+}
+
+// parses the banner and the size line
+func (reader *MatrixMarketReader) Header() (*MatrixMarketHeader, error) {
+	if reader.header != nil {
+		return reader.header, nil
+	}
+
+	if !reader.scanner.Scan() {
+		if err := reader.scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("line 1: empty file")
+	}
+	reader.line++
+
+	banner := strings.Fields(strings.ToLower(reader.scanner.Text()))
+	if len(banner) != 5 || banner[0] != strings.ToLower(MATRIX_MARKET_BANNER) || banner[1] != "matrix" {
+		return nil, fmt.Errorf("line 1: expected \"%v matrix <format> <field> <symmetry>\"", MATRIX_MARKET_BANNER)
+	}
+
+	header := &MatrixMarketHeader{
+		Format:   MatrixMarketFormat(banner[2]),
+		Field:    MatrixMarketField(banner[3]),
+		Symmetry: MatrixMarketSymmetry(banner[4]),
+	}
+	if err := header.validate(); err != nil {
+		return nil, fmt.Errorf("line 1: %v", err)
+	}
+
+	tokens, err := reader.nextLine()
+	if err != nil {
+		return nil, err
+	}
+
+	expected := 2
+	if header.Format == MATRIX_MARKET_COORDINATE {
+		expected = 3
+	}
+	if len(tokens) != expected {
+		return nil, fmt.Errorf("line %v: expected %v numbers in the size line, got %v", reader.line, expected, len(tokens))
+	}
+
+	sizes := make([]int, 0, expected)
+	for _, token := range tokens {
+		size, err := reader.parseInt(token)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, fmt.Errorf("line %v: negative size %v", reader.line, size)
+		}
+		// the number of entries isn't allocated, only the dimensions are limited
+		if len(sizes) < 2 && size > MAX_DIMENSION {
+			return nil, fmt.Errorf("line %v: size %v is too big, maximum is %v", reader.line, size, MAX_DIMENSION)
+		}
+		sizes = append(sizes, size)
+	}
+
+	reader.rows, reader.cols = sizes[0], sizes[1]
+	if header.Format == MATRIX_MARKET_COORDINATE {
+		reader.entries = sizes[2]
+	}
+	if header.Symmetry != MATRIX_MARKET_GENERAL && reader.rows != reader.cols {
+		return nil, fmt.Errorf("line %v: %v matrix must be square, got %v x %v",
+			reader.line, header.Symmetry, reader.rows, reader.cols)
+	}
+
+	reader.header = header
+	return header, nil
+}
+
+// symmetric and skew-symmetric entries are mirrored
+func (reader *MatrixMarketReader) setEntry(data [][]float64, i, j int, value float64) error {
+	switch reader.header.Symmetry {
+	case MATRIX_MARKET_SYMMETRIC:
+		if i < j {
+			return fmt.Errorf("line %v: entry (%v, %v) is above the diagonal", reader.line, i+1, j+1)
+		}
+		data[i][j] += value
+		if i != j {
+			data[j][i] += value
+		}
+	case MATRIX_MARKET_SKEW_SYMMETRIC:
+		if i <= j {
+			return fmt.Errorf("line %v: entry (%v, %v) isn't below the diagonal", reader.line, i+1, j+1)
+		}
+		data[i][j] += value
+		data[j][i] -= value
+	default:
+		// duplicate coordinate entries are summed
+		data[i][j] += value
+	}
+	return nil
+}
+
+func (reader *MatrixMarketReader) readCoordinate(data [][]float64) error {
+	expected := 3
+	if reader.header.Field == MATRIX_MARKET_PATTERN {
+		expected = 2
+	}
+
+	for k := 0; k < reader.entries; k++ {
+		tokens, err := reader.nextLine()
+		if err != nil {
+			return err
+		}
+		if len(tokens) != expected {
+			return fmt.Errorf("line %v: expected %v numbers in the entry, got %v", reader.line, expected, len(tokens))
+		}
+
+		i, err := reader.parseInt(tokens[0])
+		if err != nil {
+			return err
+		}
+		j, err := reader.parseInt(tokens[1])
+		if err != nil {
+			return err
+		}
+		if i < 1 || i > reader.rows || j < 1 || j > reader.cols {
+			return fmt.Errorf("line %v: entry (%v, %v) is out of %v x %v matrix", reader.line, i, j, reader.rows, reader.cols)
+		}
+
+		var value float64 = 1
+		if reader.header.Field != MATRIX_MARKET_PATTERN {
+			if value, err = reader.parseValue(tokens[2]); err != nil {
+				return err
+			}
+		}
+
+		if err := reader.setEntry(data, i-1, j-1, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// array entries are stored by columns, only the lower triangle for symmetric matrices
+func (reader *MatrixMarketReader) readArray(data [][]float64) error {
+	for j := 0; j < reader.cols; j++ {
+		first := 0
+		switch reader.header.Symmetry {
+		case MATRIX_MARKET_SYMMETRIC:
+			first = j
+		case MATRIX_MARKET_SKEW_SYMMETRIC:
+			first = j + 1
+		}
+
+		for i := first; i < reader.rows; i++ {
+			tokens, err := reader.nextLine()
+			if err != nil {
+				return err
+			}
+			if len(tokens) != 1 {
+				return fmt.Errorf("line %v: expected 1 number in the entry, got %v", reader.line, len(tokens))
+			}
+
+			value, err := reader.parseValue(tokens[0])
+			if err != nil {
+				return err
+			}
+			if err := reader.setEntry(data, i, j, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (reader *MatrixMarketReader) readData() ([][]float64, error) {
+	if _, err := reader.Header(); err != nil {
+		return nil, err
+	}
+	if reader.consumed {
+		return nil, fmt.Errorf("matrix market file holds a single object")
+	}
+	reader.consumed = true
+
+	data := make([][]float64, reader.rows)
+	for i := range data {
+		data[i] = make([]float64, reader.cols)
+	}
+
+	var err error
+	if reader.header.Format == MATRIX_MARKET_COORDINATE {
+		err = reader.readCoordinate(data)
+	} else {
+		err = reader.readArray(data)
+	}
+	return data, err
+}
+
+func (reader *MatrixMarketReader) readVector(size int) ([]float64, error) {
+	if _, err := reader.Header(); err != nil {
+		return nil, err
+	}
+	if !(reader.rows == 1 && reader.cols == size) && !(reader.cols == 1 && reader.rows == size) {
+		return nil, fmt.Errorf("expected vector of size %v, got %v x %v matrix", size, reader.rows, reader.cols)
+	}
+
+	data, err := reader.readData()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]float64, 0, size)
+	for i := range data {
+		result = append(result, data[i]...)
+	}
+	return result, nil
+}
+
+// dimension of the square matrix or the length of the vector
+func (reader *MatrixMarketReader) ReadDimension() (int, error) {
+	if _, err := reader.Header(); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case reader.rows == reader.cols:
+		return reader.rows, nil
+	case reader.rows == 1:
+		return reader.cols, nil
+	case reader.cols == 1:
+		return reader.rows, nil
+	}
+	return 0, fmt.Errorf("matrix isn't square, got %v x %v", reader.rows, reader.cols)
+}
+
+func (reader *MatrixMarketReader) ReadMatrix(size int) (*matrix.SquareMatrix, error) {
+	if _, err := reader.Header(); err != nil {
+		return &matrix.SquareMatrix{}, err
+	}
+	if reader.rows != size || reader.cols != size {
+		return &matrix.SquareMatrix{}, fmt.Errorf("expected %v x %v matrix, got %v x %v", size, size, reader.rows, reader.cols)
+	}
+
+	data, err := reader.readData()
+	if err != nil {
+		return &matrix.SquareMatrix{}, err
+	}
+	return matrix.NewSquareMatrix(data)
+}
+
+func (reader *MatrixMarketReader) ReadRow(size int) (*matrix.Row, error) {
+	row, err := reader.readVector(size)
+	return matrix.NewRow(row), err
+}
+
+func (reader *MatrixMarketReader) ReadColumn(size int) (*matrix.Column, error) {
+	col, err := reader.readVector(size)
+	return matrix.NewColumn(col), err
+}
+
+func (reader *MatrixMarketReader) Close() error {
+	return reader.file.Close()
+}
+
+// writes a single matrix (or vector) to the Matrix Market file,
+// the first error is kept and returned by Close
+type MatrixMarketWriter struct {
+	file   *os.File
+	writer *bufio.Writer
+	header MatrixMarketHeader
+
+	written bool
+	err     error
+}
+
+func NewMatrixMarketWriter(filename string, header MatrixMarketHeader) (*MatrixMarketWriter, error) {
+	matrixWriter := MatrixMarketWriter{header: header}
+	if err := header.validate(); err != nil {
+		return &matrixWriter, err
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return &matrixWriter, err
+	}
+
+	matrixWriter.file = file
+	matrixWriter.writer = bufio.NewWriter(file)
+	return &matrixWriter, nil
+}
+
+func (writer *MatrixMarketWriter) formatValue(value float64) (string, error) {
+	if writer.header.Field == MATRIX_MARKET_INTEGER {
+		if value != math.Trunc(value) || math.IsInf(value, 0) {
+			return "", fmt.Errorf("value %v isn't integer", value)
+		}
+		return strconv.FormatInt(int64(value), 10), nil
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64), nil
+}
+
+func (writer *MatrixMarketWriter) checkSymmetry(data [][]float64) error {
+	for i := range data {
+		for j := 0; j <= i; j++ {
+			switch writer.header.Symmetry {
+			case MATRIX_MARKET_SYMMETRIC:
+				if data[i][j] != data[j][i] {
+					return fmt.Errorf("matrix isn't symmetric at (%v, %v)", i+1, j+1)
+				}
+			case MATRIX_MARKET_SKEW_SYMMETRIC:
+				if data[i][j] != -data[j][i] {
+					return fmt.Errorf("matrix isn't skew-symmetric at (%v, %v)", i+1, j+1)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// stored part of the matrix: everything, the lower triangle or the strictly lower triangle
+func (writer *MatrixMarketWriter) stored(i, j int) bool {
+	switch writer.header.Symmetry {
+	case MATRIX_MARKET_SYMMETRIC:
+		return i >= j
+	case MATRIX_MARKET_SKEW_SYMMETRIC:
+		return i > j
+	}
+	return true
+}
+
+func (writer *MatrixMarketWriter) write(data [][]float64, rows, cols int) error {
+	if writer.written {
+		return fmt.Errorf("matrix market file holds a single object")
+	}
+	writer.written = true
+
+	if writer.header.Symmetry != MATRIX_MARKET_GENERAL {
+		if rows != cols {
+			return fmt.Errorf("%v matrix must be square, got %v x %v", writer.header.Symmetry, rows, cols)
+		}
+		if err := writer.checkSymmetry(data); err != nil {
+			return err
+		}
+	}
+
+	var lines []string
+	if writer.header.Format == MATRIX_MARKET_ARRAY {
+		for j := 0; j < cols; j++ {
+			for i := 0; i < rows; i++ {
+				if !writer.stored(i, j) {
+					continue
+				}
+				value, err := writer.formatValue(data[i][j])
+				if err != nil {
+					return err
+				}
+				lines = append(lines, value)
+			}
+		}
+	} else {
+		for j := 0; j < cols; j++ {
+			for i := 0; i < rows; i++ {
+				if !writer.stored(i, j) || data[i][j] == 0 {
+					continue
+				}
+				if writer.header.Field == MATRIX_MARKET_PATTERN {
+					lines = append(lines, fmt.Sprintf("%v %v", i+1, j+1))
+					continue
+				}
+				value, err := writer.formatValue(data[i][j])
+				if err != nil {
+					return err
+				}
+				lines = append(lines, fmt.Sprintf("%v %v %v", i+1, j+1, value))
+			}
+		}
+	}
+
+	fmt.Fprintln(writer.writer, writer.header.String())
+	if writer.header.Format == MATRIX_MARKET_ARRAY {
+		fmt.Fprintf(writer.writer, "%v %v\n", rows, cols)
+	} else {
+		fmt.Fprintf(writer.writer, "%v %v %v\n", rows, cols, len(lines))
+	}
+	for _, line := range lines {
+		fmt.Fprintln(writer.writer, line)
+	}
+	return nil
+}
+
+func (writer *MatrixMarketWriter) keepError(err error) {
+	if writer.err == nil {
+		writer.err = err
+	}
+}
+
+func (writer *MatrixMarketWriter) WriteMatrix(squareMatrix *matrix.SquareMatrix) {
+	size := len(squareMatrix.Data)
+	writer.keepError(writer.write(squareMatrix.Data, size, size))
+}
+
+func (writer *MatrixMarketWriter) WriteRow(row *matrix.Row) {
+	writer.keepError(writer.write([][]float64{row.Data}, 1, len(row.Data)))
+}
+
+func (writer *MatrixMarketWriter) WriteColumn(column *matrix.Column) {
+	data := make([][]float64, 0, len(column.Data))
+	for _, v := range column.Data {
+		data = append(data, []float64{v})
+	}
+	writer.keepError(writer.write(data, len(column.Data), 1))
+}
+
+func (writer *MatrixMarketWriter) Close() error {
+	if writer.file == nil {
+		return writer.err
+	}
+	writer.keepError(writer.writer.Flush())
+	writer.keepError(writer.file.Close())
+	return writer.err
+}
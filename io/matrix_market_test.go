@@ -0,0 +1,172 @@
+package io
+
+import (
+	"cma-lab-go/matrix"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// file of the test directory with the given content
+func writeTempFile(t *testing.T, name string, content []byte) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(filename, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestMatrixMarketRoundTrip(t *testing.T) {
+	general := [][]float64{{1, 0, -2.5}, {0, 3, 0}, {4, 0, 1e-300}}
+	symmetric := [][]float64{{2, -1, 0}, {-1, 2, -1}, {0, -1, 2}}
+	skew := [][]float64{{0, 1, -2}, {-1, 0, 3}, {2, -3, 0}}
+	pattern := [][]float64{{1, 0}, {1, 1}}
+	tests := []struct {
+		name   string
+		header MatrixMarketHeader
+		data   [][]float64
+	}{
+		{"coordinate general", MatrixMarketHeader{MATRIX_MARKET_COORDINATE, MATRIX_MARKET_REAL, MATRIX_MARKET_GENERAL}, general},
+		{"array general", MatrixMarketHeader{MATRIX_MARKET_ARRAY, MATRIX_MARKET_REAL, MATRIX_MARKET_GENERAL}, general},
+		{"coordinate symmetric", MatrixMarketHeader{MATRIX_MARKET_COORDINATE, MATRIX_MARKET_INTEGER, MATRIX_MARKET_SYMMETRIC}, symmetric},
+		{"array symmetric", MatrixMarketHeader{MATRIX_MARKET_ARRAY, MATRIX_MARKET_REAL, MATRIX_MARKET_SYMMETRIC}, symmetric},
+		{"coordinate skew-symmetric", MatrixMarketHeader{MATRIX_MARKET_COORDINATE, MATRIX_MARKET_REAL, MATRIX_MARKET_SKEW_SYMMETRIC}, skew},
+		{"array skew-symmetric", MatrixMarketHeader{MATRIX_MARKET_ARRAY, MATRIX_MARKET_INTEGER, MATRIX_MARKET_SKEW_SYMMETRIC}, skew},
+		{"pattern", MatrixMarketHeader{MATRIX_MARKET_COORDINATE, MATRIX_MARKET_PATTERN, MATRIX_MARKET_GENERAL}, pattern},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "matrix.mtx")
+			writer, err := NewMatrixMarketWriter(filename, test.header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			squareMatrix, _ := matrix.NewSquareMatrix(test.data)
+			writer.WriteMatrix(squareMatrix)
+			if err := writer.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			reader, err := NewMatrixMarketReader(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+			header, err := reader.Header()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *header != test.header {
+				t.Errorf("header is %v, expected %v", header, test.header)
+			}
+			size, err := reader.ReadDimension()
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := reader.ReadMatrix(size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual.Data, test.data) {
+				t.Errorf("got %v, expected %v", actual.Data, test.data)
+			}
+		})
+	}
+}
+
+func TestMatrixMarketVector(t *testing.T) {
+	filename := writeTempFile(t, "vector.mtx", []byte("%%MatrixMarket matrix array real general\n% comment\n\n3 1\n1\n-2\n3.5\n"))
+	reader, err := NewMatrixMarketReader(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	size, err := reader.ReadDimension()
+	if err != nil {
+		t.Fatal(err)
+	}
+	column, err := reader.ReadColumn(size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []float64{1, -2, 3.5}; !reflect.DeepEqual(column.Data, expected) {
+		t.Errorf("got %v, expected %v", column.Data, expected)
+	}
+}
+
+func TestMatrixMarketErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{"empty", "", "empty file"},
+		{"bad banner", "%%MatrixMarket tensor array real general\n", "expected"},
+		{"unsupported field", "%%MatrixMarket matrix array complex general\n", "unsupported field"},
+		{"array pattern", "%%MatrixMarket matrix array pattern general\n", "pattern field requires coordinate format"},
+		{"no size line", "%%MatrixMarket matrix array real general\n", "unexpected end of file"},
+		{"short size line", "%%MatrixMarket matrix coordinate real general\n2 2\n", "expected 3 numbers"},
+		{"negative size", "%%MatrixMarket matrix array real general\n-2 2\n", "negative size"},
+		{"huge size", "%%MatrixMarket matrix array real general\n100000 100000\n", "too big"},
+		{"not square", "%%MatrixMarket matrix array real symmetric\n2 3\n", "must be square"},
+		{"truncated array", "%%MatrixMarket matrix array real general\n2 2\n1\n2\n3\n", "unexpected end of file"},
+		{"truncated coordinate", "%%MatrixMarket matrix coordinate real general\n2 2 3\n1 1 1\n", "unexpected end of file"},
+		{"bad number", "%%MatrixMarket matrix array real general\n1 1\nx\n", "line 3"},
+		{"bad integer", "%%MatrixMarket matrix array integer general\n1 1\n1.5\n", "line 3"},
+		{"out of range", "%%MatrixMarket matrix coordinate real general\n2 2 1\n3 1 1\n", "out of 2 x 2 matrix"},
+		{"above diagonal", "%%MatrixMarket matrix coordinate real symmetric\n2 2 1\n1 2 1\n", "above the diagonal"},
+		{"skew diagonal", "%%MatrixMarket matrix coordinate real skew-symmetric\n2 2 1\n1 1 1\n", "isn't below the diagonal"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader, err := NewMatrixMarketReader(writeTempFile(t, "matrix.mtx", []byte(test.content)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+			size, err := reader.ReadDimension()
+			if err == nil {
+				_, err = reader.ReadMatrix(size)
+			}
+			if err == nil || !strings.Contains(err.Error(), test.message) {
+				t.Errorf("got error %v, expected %q", err, test.message)
+			}
+		})
+	}
+}
+
+func TestMatrixMarketWriterErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  MatrixMarketHeader
+		data    [][]float64
+		message string
+	}{
+		{"not symmetric", MatrixMarketHeader{MATRIX_MARKET_ARRAY, MATRIX_MARKET_REAL, MATRIX_MARKET_SYMMETRIC}, [][]float64{{1, 2}, {3, 4}}, "isn't symmetric"},
+		{"not integer", MatrixMarketHeader{MATRIX_MARKET_ARRAY, MATRIX_MARKET_INTEGER, MATRIX_MARKET_GENERAL}, [][]float64{{0.5}}, "isn't integer"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			writer, err := NewMatrixMarketWriter(filepath.Join(t.TempDir(), "matrix.mtx"), test.header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			squareMatrix, _ := matrix.NewSquareMatrix(test.data)
+			writer.WriteMatrix(squareMatrix)
+			if err := writer.Close(); err == nil || !strings.Contains(err.Error(), test.message) {
+				t.Errorf("got error %v, expected %q", err, test.message)
+			}
+		})
+	}
+
+	header := MatrixMarketHeader{MATRIX_MARKET_ARRAY, MATRIX_MARKET_PATTERN, MATRIX_MARKET_GENERAL}
+	if _, err := NewMatrixMarketWriter(filepath.Join(t.TempDir(), "matrix.mtx"), header); err == nil {
+		t.Errorf("array pattern header is accepted")
+	}
+}
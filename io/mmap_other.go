@@ -0,0 +1,14 @@
+//go:build !linux
+
+package io
+
+import "os"
+
+// there is no mmap, the file is read into memory once
+func mapFile(filename string) ([]byte, error) {
+	return os.ReadFile(filename)
+}
+
+func unmapFile(data []byte) error {
+	return nil
+}
@@ -0,0 +1,586 @@
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"cma-lab-go/matrix"
+	"encoding/binary"
+	"fmt"
+	goio "io"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const NPY_MAGIC = "\x93NUMPY"
+
+// header (magic, version, length and dict) is padded to this alignment
+const NPY_ALIGNMENT = 64
+
+// longer headers are rejected, the same as the default max_header_size of numpy
+const NPY_MAX_HEADER_LENGTH = 10000
+
+const (
+	NPY_FLOAT32    = "<f4"
+	NPY_FLOAT64    = "<f8"
+	NPY_COMPLEX64  = "<c8"
+	NPY_COMPLEX128 = "<c16"
+)
+
+var (
+	npyDescrRegexp   = regexp.MustCompile(`['"]descr['"]\s*:\s*['"]([^'"]*)['"]`)
+	npyFortranRegexp = regexp.MustCompile(`['"]fortran_order['"]\s*:\s*(True|False)`)
+	npyShapeRegexp   = regexp.MustCompile(`['"]shape['"]\s*:\s*\(([^)]*)\)`)
+)
+
+// n-dimensional array, Data (and Imag for complex types) are always stored in C order,
+// FortranOrder only tells the order in the file
+type NpyArray struct {
+	// numpy type string, one of NPY_* (big endian variants are read as well)
+	Descr        string
+	FortranOrder bool
+	Shape        []int
+	Data         []float64
+	// nil for real types
+	Imag []float64
+}
+
+func NewNpyMatrix(squareMatrix *matrix.SquareMatrix) *NpyArray {
+	size := len(squareMatrix.Data)
+	data := make([]float64, 0, size*size)
+	for _, row := range squareMatrix.Data {
+		data = append(data, row...)
+	}
+	return &NpyArray{Descr: NPY_FLOAT64, Shape: []int{size, size}, Data: data}
+}
+
+func NewNpyVector(vector []float64) *NpyArray {
+	data := make([]float64, len(vector))
+	copy(data, vector)
+	return &NpyArray{Descr: NPY_FLOAT64, Shape: []int{len(vector)}, Data: data}
+}
+
+func NewNpyComplexVector(vector []complex128) *NpyArray {
+	array := &NpyArray{
+		Descr: NPY_COMPLEX128,
+		Shape: []int{len(vector)},
+		Data:  make([]float64, 0, len(vector)),
+		Imag:  make([]float64, 0, len(vector)),
+	}
+	for _, v := range vector {
+		array.Data = append(array.Data, real(v))
+		array.Imag = append(array.Imag, imag(v))
+	}
+	return array
+}
+
+func npyCount(shape []int) int {
+	count := 1
+	for _, v := range shape {
+		count *= v
+	}
+	return count
+}
+
+func (array *NpyArray) IsComplex() bool {
+	return array.Imag != nil
+}
+
+func (array *NpyArray) checkReal() error {
+	for i, v := range array.Imag {
+		if v != 0 {
+			return fmt.Errorf("array is complex, element %v has imaginary part %v", i, v)
+		}
+	}
+	return nil
+}
+
+func (array *NpyArray) SquareMatrix() (*matrix.SquareMatrix, error) {
+	if len(array.Shape) != 2 || array.Shape[0] != array.Shape[1] {
+		return &matrix.SquareMatrix{}, fmt.Errorf("expected square matrix, got shape %v", array.Shape)
+	}
+	if err := array.checkReal(); err != nil {
+		return &matrix.SquareMatrix{}, err
+	}
+
+	size := array.Shape[0]
+	data := make([][]float64, size)
+	for i := range data {
+		data[i] = make([]float64, size)
+		copy(data[i], array.Data[i*size:(i+1)*size])
+	}
+	return matrix.NewSquareMatrix(data)
+}
+
+// shapes (n), (n, 1) and (1, n) are vectors
+func (array *NpyArray) isVector() bool {
+	switch len(array.Shape) {
+	case 1:
+		return true
+	case 2:
+		return array.Shape[0] == 1 || array.Shape[1] == 1
+	}
+	return false
+}
+
+func (array *NpyArray) Vector() ([]float64, error) {
+	if !array.isVector() {
+		return nil, fmt.Errorf("expected vector, got shape %v", array.Shape)
+	}
+	if err := array.checkReal(); err != nil {
+		return nil, err
+	}
+
+	result := make([]float64, len(array.Data))
+	copy(result, array.Data)
+	return result, nil
+}
+
+func (array *NpyArray) ComplexVector() ([]complex128, error) {
+	if !array.isVector() {
+		return nil, fmt.Errorf("expected vector, got shape %v", array.Shape)
+	}
+
+	result := make([]complex128, 0, len(array.Data))
+	for i, v := range array.Data {
+		if array.IsComplex() {
+			result = append(result, complex(v, array.Imag[i]))
+		} else {
+			result = append(result, complex(v, 0))
+		}
+	}
+	return result, nil
+}
+
+// C order index of every element in Fortran order
+func fortranToC(shape []int) []int {
+	count := npyCount(shape)
+	result := make([]int, 0, count)
+	index := make([]int, len(shape))
+	for k := 0; k < count; k++ {
+		position := 0
+		for d := range shape {
+			position = position*shape[d] + index[d]
+		}
+		result = append(result, position)
+
+		// the first index changes fastest
+		for d := 0; d < len(shape); d++ {
+			index[d]++
+			if index[d] < shape[d] {
+				break
+			}
+			index[d] = 0
+		}
+	}
+	return result
+}
+
+func parseNpyHeader(header string, array *NpyArray) error {
+	descr := npyDescrRegexp.FindStringSubmatch(header)
+	fortran := npyFortranRegexp.FindStringSubmatch(header)
+	shape := npyShapeRegexp.FindStringSubmatchIndex(header)
+	if descr == nil || fortran == nil || shape == nil {
+		return fmt.Errorf("invalid npy header %q", header)
+	}
+
+	array.Descr = descr[1]
+	array.FortranOrder = fortran[1] == "True"
+	array.Shape = []int{}
+
+	// dimensions are limited like in the text formats, so is the number of elements
+	// (the data is allocated by the shape), columns are counted in the header
+	count, offset := 1, shape[2]
+	for _, field := range strings.Split(header[shape[2]:shape[3]], ",") {
+		token := strings.TrimSpace(field)
+		column := offset + strings.Index(field, token) + 1
+		offset += len(field) + 1
+		if token == "" {
+			continue
+		}
+
+		dim, err := parseDimension(token, 1, column)
+		if err != nil {
+			return err
+		}
+		if dim > 0 && count > MAX_DIMENSION*MAX_DIMENSION/dim {
+			return &ParseError{Kind: HUGE_DIMENSION_ERROR, Line: 1, Column: column, Token: token,
+				Err: fmt.Errorf("shape has more than %v elements", MAX_DIMENSION*MAX_DIMENSION)}
+		}
+		count *= dim
+		array.Shape = append(array.Shape, dim)
+	}
+	return nil
+}
+
+// byte order, element size and whether the type is complex
+func parseNpyDescr(descr string) (binary.ByteOrder, int, bool, error) {
+	if len(descr) < 3 {
+		return nil, 0, false, fmt.Errorf("unsupported npy type %q", descr)
+	}
+
+	var order binary.ByteOrder
+	switch descr[0] {
+	case '<', '=', '|':
+		order = binary.LittleEndian
+	case '>':
+		order = binary.BigEndian
+	default:
+		return nil, 0, false, fmt.Errorf("unsupported npy byte order %q", descr)
+	}
+
+	switch descr[1:] {
+	case "f4":
+		return order, 4, false, nil
+	case "f8":
+		return order, 8, false, nil
+	case "c8":
+		return order, 8, true, nil
+	case "c16":
+		return order, 16, true, nil
+	}
+	return nil, 0, false, fmt.Errorf("unsupported npy type %q", descr)
+}
+
+func ReadNpy(reader goio.Reader) (*NpyArray, error) {
+	prefix := make([]byte, len(NPY_MAGIC)+2)
+	if _, err := goio.ReadFull(reader, prefix); err != nil {
+		return nil, fmt.Errorf("npy prefix: %w", err)
+	}
+	if string(prefix[:len(NPY_MAGIC)]) != NPY_MAGIC {
+		return nil, fmt.Errorf("not a npy file")
+	}
+
+	var headerLength int
+	switch major := prefix[len(NPY_MAGIC)]; major {
+	case 1:
+		var length uint16
+		if err := binary.Read(reader, binary.LittleEndian, &length); err != nil {
+			return nil, fmt.Errorf("npy header length: %w", err)
+		}
+		headerLength = int(length)
+	case 2, 3:
+		var length uint32
+		if err := binary.Read(reader, binary.LittleEndian, &length); err != nil {
+			return nil, fmt.Errorf("npy header length: %w", err)
+		}
+		headerLength = int(length)
+	default:
+		return nil, fmt.Errorf("unsupported npy version %v", major)
+	}
+	if headerLength > NPY_MAX_HEADER_LENGTH {
+		return nil, fmt.Errorf("npy header length %v is too big, maximum is %v", headerLength, NPY_MAX_HEADER_LENGTH)
+	}
+
+	header := make([]byte, headerLength)
+	if _, err := goio.ReadFull(reader, header); err != nil {
+		return nil, fmt.Errorf("npy header: %w", err)
+	}
+
+	array := &NpyArray{}
+	if err := parseNpyHeader(string(header), array); err != nil {
+		return nil, err
+	}
+	order, size, isComplex, err := parseNpyDescr(array.Descr)
+	if err != nil {
+		return nil, err
+	}
+
+	// the buffer grows with the data read, a short file doesn't allocate the whole shape
+	count := npyCount(array.Shape)
+	data, err := goio.ReadAll(goio.LimitReader(reader, int64(count)*int64(size)))
+	if err != nil {
+		return nil, fmt.Errorf("npy data of %v elements: %w", count, err)
+	}
+	if len(data) < count*size {
+		return nil, fmt.Errorf("npy data of %v elements: %w", count, goio.ErrUnexpectedEOF)
+	}
+
+	// complex numbers are pairs of floats
+	partSize := size
+	if isComplex {
+		partSize /= 2
+	}
+	decode := func(offset int) float64 {
+		if partSize == 4 {
+			return float64(math.Float32frombits(order.Uint32(data[offset:])))
+		}
+		return math.Float64frombits(order.Uint64(data[offset:]))
+	}
+
+	positions := make([]int, count)
+	for k := range positions {
+		positions[k] = k
+	}
+	if array.FortranOrder {
+		positions = fortranToC(array.Shape)
+	}
+
+	array.Data = make([]float64, count)
+	if isComplex {
+		array.Imag = make([]float64, count)
+	}
+	for k := 0; k < count; k++ {
+		array.Data[positions[k]] = decode(k * size)
+		if isComplex {
+			array.Imag[positions[k]] = decode(k*size + partSize)
+		}
+	}
+	return array, nil
+}
+
+func (array *NpyArray) header() []byte {
+	shape := make([]string, 0, len(array.Shape))
+	for _, v := range array.Shape {
+		shape = append(shape, strconv.Itoa(v))
+	}
+	// python tuple of one element has a trailing comma
+	tuple := strings.Join(shape, ", ")
+	if len(shape) == 1 {
+		tuple += ","
+	}
+
+	fortran := "False"
+	if array.FortranOrder {
+		fortran = "True"
+	}
+
+	dict := fmt.Sprintf("{'descr': '%v', 'fortran_order': %v, 'shape': (%v), }", array.Descr, fortran, tuple)
+	// magic, version (2 bytes), header length (2 bytes), dict and '\n'
+	total := len(NPY_MAGIC) + 4 + len(dict) + 1
+	padding := (NPY_ALIGNMENT - total%NPY_ALIGNMENT) % NPY_ALIGNMENT
+	return []byte(dict + strings.Repeat(" ", padding) + "\n")
+}
+
+func WriteNpy(writer goio.Writer, array *NpyArray) error {
+	order, size, isComplex, err := parseNpyDescr(array.Descr)
+	if err != nil {
+		return err
+	}
+	count := npyCount(array.Shape)
+	if len(array.Data) != count || (isComplex && len(array.Imag) != count) {
+		return fmt.Errorf("shape %v doesn't match %v elements", array.Shape, len(array.Data))
+	}
+	if !isComplex {
+		if err := array.checkReal(); err != nil {
+			return err
+		}
+	}
+
+	header := array.header()
+	if len(header) > math.MaxUint16 {
+		return fmt.Errorf("npy header is too long")
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(NPY_MAGIC)
+	buffer.Write([]byte{1, 0})
+	binary.Write(&buffer, binary.LittleEndian, uint16(len(header)))
+	buffer.Write(header)
+	if _, err := writer.Write(buffer.Bytes()); err != nil {
+		return err
+	}
+
+	positions := make([]int, count)
+	for k := range positions {
+		positions[k] = k
+	}
+	if array.FortranOrder {
+		positions = fortranToC(array.Shape)
+	}
+
+	partSize := size
+	if isComplex {
+		partSize /= 2
+	}
+	element := make([]byte, size)
+	encode := func(offset int, value float64) {
+		if partSize == 4 {
+			order.PutUint32(element[offset:], math.Float32bits(float32(value)))
+		} else {
+			order.PutUint64(element[offset:], math.Float64bits(value))
+		}
+	}
+
+	buffered := bufio.NewWriter(writer)
+	for k := 0; k < count; k++ {
+		encode(0, array.Data[positions[k]])
+		if isComplex {
+			encode(partSize, array.Imag[positions[k]])
+		}
+		if _, err := buffered.Write(element); err != nil {
+			return err
+		}
+	}
+	return buffered.Flush()
+}
+
+// MatrixReader over a single array: the first Read* call after ReadDimension returns it
+type NpyMatrixReader struct {
+	file *os.File
+
+	array    *NpyArray
+	consumed bool
+}
+
+func NewNpyMatrixReader(filename string) (*NpyMatrixReader, error) {
+	matrixReader := NpyMatrixReader{}
+	file, err := os.Open(filename)
+	if err != nil {
+		return &matrixReader, err
+	}
+
+	matrixReader.file = file
+	return &matrixReader, nil
+}
+
+func (reader *NpyMatrixReader) ReadArray() (*NpyArray, error) {
+	if reader.array == nil {
+		array, err := ReadNpy(bufio.NewReader(reader.file))
+		if err != nil {
+			return nil, err
+		}
+		reader.array = array
+	}
+	return reader.array, nil
+}
+
+func (reader *NpyMatrixReader) next() (*NpyArray, error) {
+	array, err := reader.ReadArray()
+	if err != nil {
+		return nil, err
+	}
+	if reader.consumed {
+		return nil, fmt.Errorf("npy file holds a single array")
+	}
+	reader.consumed = true
+	return array, nil
+}
+
+func npyDimension(array *NpyArray) (int, error) {
+	switch {
+	case len(array.Shape) == 2 && array.Shape[0] == array.Shape[1]:
+		return array.Shape[0], nil
+	case array.isVector():
+		return npyCount(array.Shape), nil
+	}
+	return 0, fmt.Errorf("expected square matrix or vector, got shape %v", array.Shape)
+}
+
+func npyMatrix(array *NpyArray, size int) (*matrix.SquareMatrix, error) {
+	result, err := array.SquareMatrix()
+	if err != nil {
+		return result, err
+	}
+	if len(result.Data) != size {
+		return &matrix.SquareMatrix{}, fmt.Errorf("expected %v x %v matrix, got shape %v", size, size, array.Shape)
+	}
+	return result, nil
+}
+
+func npyVector(array *NpyArray, size int) ([]float64, error) {
+	result, err := array.Vector()
+	if err != nil {
+		return nil, err
+	}
+	if len(result) != size {
+		return nil, fmt.Errorf("expected vector of size %v, got shape %v", size, array.Shape)
+	}
+	return result, nil
+}
+
+func (reader *NpyMatrixReader) ReadDimension() (int, error) {
+	array, err := reader.ReadArray()
+	if err != nil {
+		return 0, err
+	}
+	return npyDimension(array)
+}
+
+func (reader *NpyMatrixReader) ReadMatrix(size int) (*matrix.SquareMatrix, error) {
+	array, err := reader.next()
+	if err != nil {
+		return &matrix.SquareMatrix{}, err
+	}
+	return npyMatrix(array, size)
+}
+
+func (reader *NpyMatrixReader) ReadRow(size int) (*matrix.Row, error) {
+	array, err := reader.next()
+	if err != nil {
+		return matrix.NewRow(nil), err
+	}
+	row, err := npyVector(array, size)
+	return matrix.NewRow(row), err
+}
+
+func (reader *NpyMatrixReader) ReadColumn(size int) (*matrix.Column, error) {
+	array, err := reader.next()
+	if err != nil {
+		return matrix.NewColumn(nil), err
+	}
+	col, err := npyVector(array, size)
+	return matrix.NewColumn(col), err
+}
+
+func (reader *NpyMatrixReader) Close() error {
+	return reader.file.Close()
+}
+
+// MatrixWriter of a single float64 array, the first error is kept and returned by Close
+type NpyMatrixWriter struct {
+	file *os.File
+
+	written bool
+	err     error
+}
+
+func NewNpyMatrixWriter(filename string) (*NpyMatrixWriter, error) {
+	matrixWriter := NpyMatrixWriter{}
+	file, err := os.Create(filename)
+	if err != nil {
+		return &matrixWriter, err
+	}
+
+	matrixWriter.file = file
+	return &matrixWriter, nil
+}
+
+func (writer *NpyMatrixWriter) WriteArray(array *NpyArray) {
+	if writer.written {
+		writer.keepError(fmt.Errorf("npy file holds a single array"))
+		return
+	}
+	writer.written = true
+	writer.keepError(WriteNpy(writer.file, array))
+}
+
+func (writer *NpyMatrixWriter) keepError(err error) {
+	if writer.err == nil {
+		writer.err = err
+	}
+}
+
+func (writer *NpyMatrixWriter) WriteMatrix(squareMatrix *matrix.SquareMatrix) {
+	writer.WriteArray(NewNpyMatrix(squareMatrix))
+}
+
+func (writer *NpyMatrixWriter) WriteRow(row *matrix.Row) {
+	array := NewNpyVector(row.Data)
+	array.Shape = []int{1, len(row.Data)}
+	writer.WriteArray(array)
+}
+
+func (writer *NpyMatrixWriter) WriteColumn(column *matrix.Column) {
+	writer.WriteArray(NewNpyVector(column.Data))
+}
+
+func (writer *NpyMatrixWriter) Close() error {
+	if writer.file == nil {
+		return writer.err
+	}
+	writer.keepError(writer.file.Close())
+	return writer.err
+}
@@ -0,0 +1,258 @@
+package io
+
+import (
+	"archive/zip"
+	"bytes"
+	"cma-lab-go/matrix"
+	"encoding/binary"
+	"errors"
+	goio "io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// npy file with the given version, header dict and raw data
+func npyFile(major byte, header string, data []byte) []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString(NPY_MAGIC)
+	buffer.Write([]byte{major, 0})
+	if major == 1 {
+		binary.Write(&buffer, binary.LittleEndian, uint16(len(header)))
+	} else {
+		binary.Write(&buffer, binary.LittleEndian, uint32(len(header)))
+	}
+	buffer.WriteString(header)
+	buffer.Write(data)
+	return buffer.Bytes()
+}
+
+func TestNpyRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		array *NpyArray
+	}{
+		{"float64 matrix", &NpyArray{Descr: NPY_FLOAT64, Shape: []int{2, 3}, Data: []float64{1, -2.5, 1e-300, 4, 5, 1.0 / 3}}},
+		{"float32 matrix", &NpyArray{Descr: NPY_FLOAT32, Shape: []int{2, 2}, Data: []float64{1, -2.5, 0.25, 4}}},
+		{"fortran order", &NpyArray{Descr: NPY_FLOAT64, FortranOrder: true, Shape: []int{2, 3, 2}, Data: []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}}},
+		{"complex128 vector", NewNpyComplexVector([]complex128{1, complex(2, -3), complex(0, 0.5)})},
+		{"complex64 vector", &NpyArray{Descr: NPY_COMPLEX64, Shape: []int{2}, Data: []float64{1, 2}, Imag: []float64{-0.5, 0}}},
+		{"empty", &NpyArray{Descr: NPY_FLOAT64, Shape: []int{0, 0}, Data: []float64{}}},
+		{"scalar", &NpyArray{Descr: NPY_FLOAT64, Shape: []int{}, Data: []float64{7}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := WriteNpy(&buffer, test.array); err != nil {
+				t.Fatal(err)
+			}
+			headerLength := int(binary.LittleEndian.Uint16(buffer.Bytes()[len(NPY_MAGIC)+2:]))
+			if (len(NPY_MAGIC)+4+headerLength)%NPY_ALIGNMENT != 0 {
+				t.Errorf("header length %v isn't aligned", headerLength)
+			}
+
+			actual, err := ReadNpy(&buffer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, test.array) {
+				t.Errorf("got %+v, expected %+v", actual, test.array)
+			}
+		})
+	}
+}
+
+func TestNpyMatrixFile(t *testing.T) {
+	data := [][]float64{{1, 2}, {3, 4}}
+	filename := filepath.Join(t.TempDir(), "matrix.npy")
+	writer, err := NewNpyMatrixWriter(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	squareMatrix, _ := matrix.NewSquareMatrix(data)
+	writer.WriteMatrix(squareMatrix)
+	writer.WriteMatrix(squareMatrix)
+	if err := writer.Close(); err == nil || !strings.Contains(err.Error(), "single array") {
+		t.Errorf("got error %v for the second matrix", err)
+	}
+
+	reader, err := NewNpyMatrixReader(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	size, err := reader.ReadDimension()
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := reader.ReadMatrix(size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(actual.Data, data) {
+		t.Errorf("got %v, expected %v", actual.Data, data)
+	}
+	if _, err := reader.ReadMatrix(size); err == nil {
+		t.Errorf("the array is read twice")
+	}
+}
+
+func TestNpyErrors(t *testing.T) {
+	header := func(descr, shape string) string {
+		return "{'descr': '" + descr + "', 'fortran_order': False, 'shape': (" + shape + "), }\n"
+	}
+	tests := []struct {
+		name    string
+		content []byte
+		message string
+	}{
+		{"empty", nil, "npy prefix"},
+		{"bad magic", []byte("\x93NUMPX\x01\x00"), "not a npy file"},
+		{"unsupported version", npyFile(4, header(NPY_FLOAT64, "1,"), nil), "unsupported npy version 4"},
+		{"no header length", []byte(NPY_MAGIC + "\x01\x00\x10"), "npy header length"},
+		{"huge header length", []byte(NPY_MAGIC + "\x02\x00\xff\xff\xff\xff"), "too big"},
+		{"truncated header", npyFile(1, header(NPY_FLOAT64, "1,"), nil)[:20], "npy header"},
+		{"invalid header", npyFile(1, "{'descr': '<f8'}\n", nil), "invalid npy header"},
+		{"unsupported type", npyFile(1, header("<i8", "1,"), make([]byte, 8)), "unsupported npy type"},
+		{"bad byte order", npyFile(1, header("!f8", "1,"), make([]byte, 8)), "unsupported npy byte order"},
+		{"bad dimension", npyFile(2, header(NPY_FLOAT64, "2, x"), nil), "bad dimension"},
+		{"negative dimension", npyFile(1, header(NPY_FLOAT64, "-2, 2"), nil), "negative dimension"},
+		{"huge dimension", npyFile(1, header(NPY_FLOAT64, "100000,"), nil), "huge dimension"},
+		{"huge shape", npyFile(3, header(NPY_FLOAT64, "16384, 16384, 2"), nil), "more than"},
+		{"truncated data", npyFile(1, header(NPY_FLOAT64, "2, 2"), make([]byte, 31)), "unexpected EOF"},
+		{"no data", npyFile(1, header(NPY_FLOAT64, "16384, 16384"), nil), "unexpected EOF"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ReadNpy(bytes.NewReader(test.content))
+			if err == nil || !strings.Contains(err.Error(), test.message) {
+				t.Errorf("got error %v, expected %q", err, test.message)
+			}
+		})
+	}
+
+	// dimensions are reported like the ones of the text formats
+	_, err := ReadNpy(bytes.NewReader(npyFile(1, header(NPY_FLOAT64, "3, -1"), nil)))
+	var parseError *ParseError
+	if !errors.As(err, &parseError) || parseError.Kind != NEGATIVE_DIMENSION_ERROR || parseError.Column != 55 {
+		t.Errorf("got error %v, expected negative dimension at column 55", err)
+	}
+}
+
+func TestNpyWriteErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		array   *NpyArray
+		message string
+	}{
+		{"unsupported type", &NpyArray{Descr: "<i4", Shape: []int{1}, Data: []float64{1}}, "unsupported npy type"},
+		{"shape mismatch", &NpyArray{Descr: NPY_FLOAT64, Shape: []int{2, 2}, Data: []float64{1}}, "doesn't match"},
+		{"complex as real", &NpyArray{Descr: NPY_FLOAT64, Shape: []int{1}, Data: []float64{1}, Imag: []float64{2}}, "array is complex"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := WriteNpy(goio.Discard, test.array); err == nil || !strings.Contains(err.Error(), test.message) {
+				t.Errorf("got error %v, expected %q", err, test.message)
+			}
+		})
+	}
+}
+
+func TestNpzRoundTrip(t *testing.T) {
+	data := [][]float64{{1, 2}, {3, 4}}
+	vector := []float64{5, 6, 7}
+	for _, compressed := range []bool{false, true} {
+		filename := filepath.Join(t.TempDir(), "arrays.npz")
+		writer, err := NewNpzMatrixWriter(filename, compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		squareMatrix, _ := matrix.NewSquareMatrix(data)
+		writer.WriteMatrix(squareMatrix)
+		writer.WriteRow(matrix.NewRow(vector))
+		writer.WriteArray("values", NewNpyComplexVector([]complex128{complex(1, 1)}))
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		reader, err := NewNpzMatrixReader(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if names := reader.Names(); !reflect.DeepEqual(names, []string{"arr_0", "arr_1", "values"}) {
+			t.Errorf("names are %v", names)
+		}
+
+		size, err := reader.ReadDimension()
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := reader.ReadMatrix(size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(actual.Data, data) {
+			t.Errorf("got %v, expected %v", actual.Data, data)
+		}
+		row, err := reader.ReadRow(len(vector))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(row.Data, vector) {
+			t.Errorf("got %v, expected %v", row.Data, vector)
+		}
+		values, err := reader.ReadArray("values")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if complexVector, _ := values.ComplexVector(); !reflect.DeepEqual(complexVector, []complex128{complex(1, 1)}) {
+			t.Errorf("got %v", complexVector)
+		}
+		// the complex array isn't a real vector
+		if _, err := reader.ReadColumn(1); err == nil {
+			t.Errorf("complex array is read as a real column")
+		}
+		if _, err := reader.ReadDimension(); !errors.Is(err, goio.EOF) {
+			t.Errorf("got error %v after the last array", err)
+		}
+		if _, err := reader.ReadArray("missing"); err == nil {
+			t.Errorf("missing array is found")
+		}
+		reader.Close()
+	}
+}
+
+func TestNpzErrors(t *testing.T) {
+	// archive with a truncated array, np.savez never writes it
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	entry, err := archive.Create("broken.npy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry.Write(npyFile(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (2, 2), }\n", make([]byte, 16)))
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewNpzMatrixReader(writeTempFile(t, "broken.npz", buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if _, err := reader.ReadDimension(); err == nil || !strings.Contains(err.Error(), "broken.npy: npy data") {
+		t.Errorf("got error %v for the truncated array", err)
+	}
+
+	if _, err := NewNpzMatrixReader(writeTempFile(t, "text.npz", []byte("not a zip archive"))); err == nil {
+		t.Errorf("text file is opened")
+	}
+	truncated := buffer.Bytes()[:buffer.Len()/2]
+	if _, err := NewNpzMatrixReader(writeTempFile(t, "truncated.npz", truncated)); err == nil {
+		t.Errorf("truncated archive is opened")
+	}
+}
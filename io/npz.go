@@ -0,0 +1,190 @@
+package io
+
+import (
+	"archive/zip"
+	"cma-lab-go/matrix"
+	"fmt"
+	goio "io"
+	"os"
+	"strings"
+)
+
+// numpy names positional arrays of np.savez like this
+const NPZ_ARRAY_NAME = "arr_%v"
+
+// MatrixReader over the arrays of the archive in their order,
+// ReadDimension loads the next array, the following Read* call returns it
+type NpzMatrixReader struct {
+	archive *zip.ReadCloser
+
+	next    int
+	pending *NpyArray
+}
+
+func NewNpzMatrixReader(filename string) (*NpzMatrixReader, error) {
+	matrixReader := NpzMatrixReader{}
+	archive, err := zip.OpenReader(filename)
+	if err != nil {
+		return &matrixReader, err
+	}
+
+	matrixReader.archive = archive
+	return &matrixReader, nil
+}
+
+// array names without the .npy suffix
+func (reader *NpzMatrixReader) Names() []string {
+	names := make([]string, 0, len(reader.archive.File))
+	for _, file := range reader.archive.File {
+		names = append(names, strings.TrimSuffix(file.Name, ".npy"))
+	}
+	return names
+}
+
+func readNpzFile(file *zip.File) (*NpyArray, error) {
+	entry, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer entry.Close()
+
+	array, err := ReadNpy(entry)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", file.Name, err)
+	}
+	return array, nil
+}
+
+func (reader *NpzMatrixReader) ReadArray(name string) (*NpyArray, error) {
+	for _, file := range reader.archive.File {
+		if file.Name == name || file.Name == name+".npy" {
+			return readNpzFile(file)
+		}
+	}
+	return nil, fmt.Errorf("no array %q in the archive", name)
+}
+
+func (reader *NpzMatrixReader) nextArray() (*NpyArray, error) {
+	if reader.pending != nil {
+		array := reader.pending
+		reader.pending = nil
+		return array, nil
+	}
+
+	if reader.next >= len(reader.archive.File) {
+		return nil, fmt.Errorf("no more arrays in the archive: %w", goio.EOF)
+	}
+	reader.next++
+	return readNpzFile(reader.archive.File[reader.next-1])
+}
+
+func (reader *NpzMatrixReader) ReadDimension() (int, error) {
+	array, err := reader.nextArray()
+	if err != nil {
+		return 0, err
+	}
+	reader.pending = array
+	return npyDimension(array)
+}
+
+func (reader *NpzMatrixReader) ReadMatrix(size int) (*matrix.SquareMatrix, error) {
+	array, err := reader.nextArray()
+	if err != nil {
+		return &matrix.SquareMatrix{}, err
+	}
+	return npyMatrix(array, size)
+}
+
+func (reader *NpzMatrixReader) ReadRow(size int) (*matrix.Row, error) {
+	array, err := reader.nextArray()
+	if err != nil {
+		return matrix.NewRow(nil), err
+	}
+	row, err := npyVector(array, size)
+	return matrix.NewRow(row), err
+}
+
+func (reader *NpzMatrixReader) ReadColumn(size int) (*matrix.Column, error) {
+	array, err := reader.nextArray()
+	if err != nil {
+		return matrix.NewColumn(nil), err
+	}
+	col, err := npyVector(array, size)
+	return matrix.NewColumn(col), err
+}
+
+func (reader *NpzMatrixReader) Close() error {
+	return reader.archive.Close()
+}
+
+// MatrixWriter to the archive, unnamed arrays are called arr_0, arr_1, ... like np.savez does
+// the first error is kept and returned by Close
+type NpzMatrixWriter struct {
+	file    *os.File
+	archive *zip.Writer
+	// deflate entries like np.savez_compressed
+	compressed bool
+
+	count int
+	err   error
+}
+
+func NewNpzMatrixWriter(filename string, compressed bool) (*NpzMatrixWriter, error) {
+	matrixWriter := NpzMatrixWriter{compressed: compressed}
+	file, err := os.Create(filename)
+	if err != nil {
+		return &matrixWriter, err
+	}
+
+	matrixWriter.file = file
+	matrixWriter.archive = zip.NewWriter(file)
+	return &matrixWriter, nil
+}
+
+func (writer *NpzMatrixWriter) keepError(err error) {
+	if writer.err == nil {
+		writer.err = err
+	}
+}
+
+func (writer *NpzMatrixWriter) WriteArray(name string, array *NpyArray) {
+	method := zip.Store
+	if writer.compressed {
+		method = zip.Deflate
+	}
+
+	entry, err := writer.archive.CreateHeader(&zip.FileHeader{Name: name + ".npy", Method: method})
+	if err != nil {
+		writer.keepError(err)
+		return
+	}
+	writer.keepError(WriteNpy(entry, array))
+}
+
+func (writer *NpzMatrixWriter) writeUnnamed(array *NpyArray) {
+	writer.WriteArray(fmt.Sprintf(NPZ_ARRAY_NAME, writer.count), array)
+	writer.count++
+}
+
+func (writer *NpzMatrixWriter) WriteMatrix(squareMatrix *matrix.SquareMatrix) {
+	writer.writeUnnamed(NewNpyMatrix(squareMatrix))
+}
+
+func (writer *NpzMatrixWriter) WriteRow(row *matrix.Row) {
+	array := NewNpyVector(row.Data)
+	array.Shape = []int{1, len(row.Data)}
+	writer.writeUnnamed(array)
+}
+
+func (writer *NpzMatrixWriter) WriteColumn(column *matrix.Column) {
+	writer.writeUnnamed(NewNpyVector(column.Data))
+}
+
+func (writer *NpzMatrixWriter) Close() error {
+	if writer.file == nil {
+		return writer.err
+	}
+	writer.keepError(writer.archive.Close())
+	writer.keepError(writer.file.Close())
+	return writer.err
+}
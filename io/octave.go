@@ -0,0 +1,261 @@
+package io
+
+import (
+	"bufio"
+	"fmt"
+	goio "io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Octave "save -text" format: every variable is a block of "# key: value" lines followed by data
+const (
+	OCTAVE_MATRIX         = "matrix"
+	OCTAVE_COMPLEX_MATRIX = "complex matrix"
+	OCTAVE_SCALAR         = "scalar"
+	OCTAVE_COMPLEX_SCALAR = "complex scalar"
+)
+
+type OctaveMatrixReader struct {
+	variableMatrixReader
+	file    *os.File
+	scanner *bufio.Scanner
+	line    int
+}
+
+func NewOctaveMatrixReader(filename string) (*OctaveMatrixReader, error) {
+	matrixReader := &OctaveMatrixReader{}
+	file, err := os.Open(filename)
+	if err != nil {
+		return matrixReader, err
+	}
+
+	matrixReader.file = file
+	matrixReader.scanner = bufio.NewScanner(file)
+	matrixReader.scanner.Buffer(nil, 1<<30)
+	matrixReader.readVariable = matrixReader.ReadVariable
+	return matrixReader, nil
+}
+
+func (reader *OctaveMatrixReader) nextLine() (string, bool, error) {
+	if !reader.scanner.Scan() {
+		return "", false, reader.scanner.Err()
+	}
+	reader.line++
+	return strings.TrimSpace(reader.scanner.Text()), true, nil
+}
+
+// "# key: value" => key, value
+func parseOctaveKey(line string) (string, string, bool) {
+	if !strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimSpace(strings.TrimPrefix(line, "#")), ":", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), true
+}
+
+// "1.5" or "(1.5,-2)" for complex values
+func (reader *OctaveMatrixReader) parseValue(token string, isComplex bool) (float64, float64, error) {
+	if !isComplex {
+		value, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("line %v: %v", reader.line, err)
+		}
+		return value, 0, nil
+	}
+
+	if !strings.HasPrefix(token, "(") || !strings.HasSuffix(token, ")") {
+		return 0, 0, fmt.Errorf("line %v: expected (re,im), got %q", reader.line, token)
+	}
+	parts := strings.Split(token[1:len(token)-1], ",")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("line %v: expected (re,im), got %q", reader.line, token)
+	}
+	re, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("line %v: %v", reader.line, err)
+	}
+	im, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("line %v: %v", reader.line, err)
+	}
+	return re, im, nil
+}
+
+func (reader *OctaveMatrixReader) readData(variable *Variable, isComplex bool) error {
+	// the data grows with the lines read, a short file doesn't allocate the whole size
+	variable.Data = []float64{}
+	if isComplex {
+		variable.Imag = []float64{}
+	}
+
+	for i := 0; i < variable.Rows; i++ {
+		line, ok, err := reader.nextLine()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("line %v: variable %v: %w", reader.line, variable.Name, goio.ErrUnexpectedEOF)
+		}
+
+		tokens := strings.Fields(line)
+		if len(tokens) != variable.Cols {
+			return fmt.Errorf("line %v: expected %v values, got %v", reader.line, variable.Cols, len(tokens))
+		}
+		for _, token := range tokens {
+			re, im, err := reader.parseValue(token, isComplex)
+			if err != nil {
+				return err
+			}
+			variable.Data = append(variable.Data, re)
+			if isComplex {
+				variable.Imag = append(variable.Imag, im)
+			}
+		}
+	}
+	return nil
+}
+
+// next variable of the file, io.EOF if there are no more variables
+func (reader *OctaveMatrixReader) ReadVariable() (*Variable, error) {
+	variable := &Variable{}
+	for variable.Name == "" {
+		line, ok, err := reader.nextLine()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, goio.EOF
+		}
+		if key, value, ok := parseOctaveKey(line); ok && key == "name" {
+			variable.Name = value
+		}
+	}
+
+	keys := map[string]string{}
+	for {
+		line, ok, err := reader.nextLine()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("line %v: variable %v: %w", reader.line, variable.Name, goio.ErrUnexpectedEOF)
+		}
+
+		key, value, ok := parseOctaveKey(line)
+		if !ok {
+			return nil, fmt.Errorf("line %v: expected \"# key: value\", got %q", reader.line, line)
+		}
+		keys[key] = value
+
+		switch keys["type"] {
+		case OCTAVE_SCALAR, OCTAVE_COMPLEX_SCALAR:
+			variable.Rows, variable.Cols = 1, 1
+			return variable, reader.readData(variable, keys["type"] == OCTAVE_COMPLEX_SCALAR)
+		case OCTAVE_MATRIX, OCTAVE_COMPLEX_MATRIX:
+			if _, ok := keys["rows"]; !ok {
+				continue
+			}
+			if _, ok := keys["columns"]; !ok {
+				continue
+			}
+			if variable.Rows, err = strconv.Atoi(keys["rows"]); err != nil || variable.Rows < 0 {
+				return nil, fmt.Errorf("line %v: bad rows %q", reader.line, keys["rows"])
+			}
+			if variable.Cols, err = strconv.Atoi(keys["columns"]); err != nil || variable.Cols < 0 {
+				return nil, fmt.Errorf("line %v: bad columns %q", reader.line, keys["columns"])
+			}
+			// like in the other formats
+			if variable.Rows > MAX_DIMENSION || variable.Cols > MAX_DIMENSION {
+				return nil, fmt.Errorf("line %v: size %v x %v is too big, maximum is %v",
+					reader.line, variable.Rows, variable.Cols, MAX_DIMENSION)
+			}
+			return variable, reader.readData(variable, keys["type"] == OCTAVE_COMPLEX_MATRIX)
+		case "":
+			continue
+		default:
+			return nil, fmt.Errorf("line %v: variable %v has unsupported type %q", reader.line, variable.Name, keys["type"])
+		}
+	}
+}
+
+func (reader *OctaveMatrixReader) Close() error {
+	return reader.file.Close()
+}
+
+// the first error is kept and returned by Close
+type OctaveMatrixWriter struct {
+	variableMatrixWriter
+	file   *os.File
+	writer *bufio.Writer
+
+	err error
+}
+
+func NewOctaveMatrixWriter(filename string) (*OctaveMatrixWriter, error) {
+	matrixWriter := &OctaveMatrixWriter{}
+	file, err := os.Create(filename)
+	if err != nil {
+		return matrixWriter, err
+	}
+
+	matrixWriter.file = file
+	matrixWriter.writer = bufio.NewWriter(file)
+	matrixWriter.writeVariable = matrixWriter.WriteVariable
+	matrixWriter.fprintf("# Created by cma-lab-go\n")
+	return matrixWriter, nil
+}
+
+func (writer *OctaveMatrixWriter) keepError(err error) {
+	if writer.err == nil {
+		writer.err = err
+	}
+}
+
+func (writer *OctaveMatrixWriter) fprintf(format string, args ...interface{}) {
+	_, err := fmt.Fprintf(writer.writer, format, args...)
+	writer.keepError(err)
+}
+
+func formatOctaveValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func (writer *OctaveMatrixWriter) WriteVariable(variable *Variable) {
+	if writer.err != nil {
+		return
+	}
+
+	kind := OCTAVE_MATRIX
+	if variable.IsComplex() {
+		kind = OCTAVE_COMPLEX_MATRIX
+	}
+	writer.fprintf("# name: %v\n# type: %v\n# rows: %v\n# columns: %v\n",
+		variable.Name, kind, variable.Rows, variable.Cols)
+
+	for i := 0; i < variable.Rows && writer.err == nil; i++ {
+		for j := 0; j < variable.Cols; j++ {
+			k := i*variable.Cols + j
+			if variable.IsComplex() {
+				writer.fprintf(" (%v,%v)", formatOctaveValue(variable.Data[k]), formatOctaveValue(variable.Imag[k]))
+			} else {
+				writer.fprintf(" %v", formatOctaveValue(variable.Data[k]))
+			}
+		}
+		writer.fprintf("\n")
+	}
+	writer.fprintf("\n\n")
+}
+
+func (writer *OctaveMatrixWriter) Close() error {
+	if writer.file == nil {
+		return writer.err
+	}
+	writer.keepError(writer.writer.Flush())
+	writer.keepError(writer.file.Close())
+	return writer.err
+}
@@ -0,0 +1,63 @@
+package io
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOctaveScalars(t *testing.T) {
+	content := "# Created by Octave\n# name: x\n# type: scalar\n2.5\n\n\n" +
+		"# name: z\n# type: complex scalar\n(1,-2)\n\n\n" +
+		"# name: M\n# type: complex matrix\n# rows: 1\n# columns: 2\n (1,0) (0,1)\n"
+	reader, err := NewOctaveMatrixReader(writeTempFile(t, "scalars.txt", []byte(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	expected := []*Variable{
+		{Name: "x", Rows: 1, Cols: 1, Data: []float64{2.5}},
+		{Name: "z", Rows: 1, Cols: 1, Data: []float64{1}, Imag: []float64{-2}},
+		{Name: "M", Rows: 1, Cols: 2, Data: []float64{1, 0}, Imag: []float64{0, 1}},
+	}
+	for _, variable := range expected {
+		actual, err := reader.ReadVariable()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkVariable(t, actual, variable)
+	}
+}
+
+func TestOctaveErrors(t *testing.T) {
+	header := "# name: A\n# type: matrix\n# rows: 2\n# columns: 2\n"
+	tests := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{"no key", "# name: A\n1 2\n", "expected \"# key: value\""},
+		{"unsupported type", "# name: A\n# type: cell\n", "unsupported type \"cell\""},
+		{"bad rows", "# name: A\n# type: matrix\n# rows: x\n# columns: 2\n", "bad rows"},
+		{"negative columns", "# name: A\n# type: matrix\n# rows: 2\n# columns: -2\n", "bad columns"},
+		{"huge size", "# name: A\n# type: matrix\n# rows: 100000\n# columns: 100000\n", "too big"},
+		{"truncated keys", "# name: A\n# type: matrix\n", "unexpected EOF"},
+		{"truncated data", header + " 1 2\n", "unexpected EOF"},
+		{"short row", header + " 1 2\n 3\n", "line 6: expected 2 values, got 1"},
+		{"bad number", header + " 1 2\n 3 x\n", "line 6"},
+		{"bad complex", "# name: z\n# type: complex scalar\n1,2\n", "expected (re,im)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader, err := NewOctaveMatrixReader(writeTempFile(t, "matrix.txt", []byte(test.content)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+			if _, err := reader.ReadVariable(); err == nil || !strings.Contains(err.Error(), test.message) {
+				t.Errorf("got error %v, expected %q", err, test.message)
+			}
+		})
+	}
+}
@@ -3,45 +3,161 @@ package io
 import (
 	"bufio"
 	"cma-lab-go/matrix"
-	"io"
+	"fmt"
+	goio "io"
 	"os"
 	"strconv"
+	"strings"
+	"unicode"
 )
 
+// dimensions above this value are rejected, such matrices don't fit in memory anyway
+const MAX_DIMENSION = 1 << 14
+
+// the rest of the line after it is skipped
+const COMMENT_PREFIX = '#'
+
 type FileMatrixReader struct {
-	file    *os.File
-	scanner *bufio.Scanner
+	file   *os.File
+	reader *bufio.Reader
+	// position of the next rune
+	line, column int
 }
 
 func NewFileMatrixReader(filename string) (*FileMatrixReader, error) {
-	matrixReader := FileMatrixReader{}
+	matrixReader := FileMatrixReader{line: 1, column: 1}
 	file, err := os.Open(filename)
 	if err != nil {
 		return &matrixReader, err
 	}
 
 	matrixReader.file = file
-	matrixReader.scanner = bufio.NewScanner(file)
-	matrixReader.scanner.Split(bufio.ScanWords)
+	matrixReader.reader = bufio.NewReader(file)
 	return &matrixReader, nil
 }
 
+// reads from any stream (e.g. os.Stdin), Close doesn't close it
+func NewStreamMatrixReader(stream goio.Reader) *FileMatrixReader {
+	return &FileMatrixReader{reader: bufio.NewReader(stream), line: 1, column: 1}
+}
+
+func (reader *FileMatrixReader) readRune() (rune, error) {
+	r, _, err := reader.reader.ReadRune()
+	if err != nil {
+		return r, err
+	}
+	if r == '\n' {
+		reader.line++
+		reader.column = 1
+	} else {
+		reader.column++
+	}
+	return r, nil
+}
+
+func (reader *FileMatrixReader) unreadRune() {
+	reader.reader.UnreadRune()
+	reader.column--
+}
+
+// next whitespace separated token, comments are skipped
+// returns the token with its position, goio.EOF at the end of input
+func (reader *FileMatrixReader) nextToken() (string, int, int, error) {
+	for {
+		r, err := reader.readRune()
+		if err != nil {
+			return "", reader.line, reader.column, err
+		}
+
+		if r == COMMENT_PREFIX {
+			for r != '\n' {
+				if r, err = reader.readRune(); err != nil {
+					return "", reader.line, reader.column, err
+				}
+			}
+			continue
+		}
+		if unicode.IsSpace(r) {
+			continue
+		}
+
+		line, column := reader.line, reader.column-1
+		var token strings.Builder
+		for !unicode.IsSpace(r) && r != COMMENT_PREFIX {
+			token.WriteRune(r)
+			if r, err = reader.readRune(); err != nil {
+				if err == goio.EOF {
+					break
+				}
+				return "", reader.line, reader.column, err
+			}
+		}
+		if err == nil && r == COMMENT_PREFIX {
+			reader.unreadRune()
+		}
+		return token.String(), line, column, nil
+	}
+}
+
+// inside of the matrix or vector the end of input means truncation
+func (reader *FileMatrixReader) inputError(err error, line, column int) *ParseError {
+	if err == goio.EOF {
+		return &ParseError{Kind: TRUNCATED_ERROR, Line: line, Column: column, Err: goio.ErrUnexpectedEOF}
+	}
+	return &ParseError{Kind: READ_ERROR, Line: line, Column: column, Err: err}
+}
+
+func numberError(err error) error {
+	if numError, ok := err.(*strconv.NumError); ok {
+		return numError.Err
+	}
+	return err
+}
+
 func (reader *FileMatrixReader) readVector(size int) ([]float64, error) {
 	result := make([]float64, 0, size)
 	for i := 0; i < size; i++ {
-		reader.scanner.Scan()
-		x, err := strconv.ParseFloat(reader.scanner.Text(), 64)
+		token, line, column, err := reader.nextToken()
 		if err != nil {
-			return result, err
+			return result, reader.inputError(err, line, column)
+		}
+
+		x, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return result, &ParseError{Kind: BAD_NUMBER_ERROR, Line: line, Column: column,
+				Token: token, Err: numberError(err)}
 		}
 		result = append(result, x)
 	}
 	return result, nil
 }
 
+// returns ParseError of EOF_ERROR kind (errors.Is(err, io.EOF) holds), if there are no more matrices
 func (reader *FileMatrixReader) ReadDimension() (int, error) {
-	reader.scanner.Scan()
-	return strconv.Atoi(reader.scanner.Text())
+	token, line, column, err := reader.nextToken()
+	if err == goio.EOF {
+		return 0, &ParseError{Kind: EOF_ERROR, Line: line, Column: column, Err: goio.EOF}
+	}
+	if err != nil {
+		return 0, reader.inputError(err, line, column)
+	}
+	return parseDimension(token, line, column)
+}
+
+func parseDimension(token string, line, column int) (int, error) {
+	dim, err := strconv.Atoi(token)
+	switch {
+	case err != nil && numberError(err) == strconv.ErrRange:
+		return 0, &ParseError{Kind: HUGE_DIMENSION_ERROR, Line: line, Column: column, Token: token, Err: numberError(err)}
+	case err != nil:
+		return 0, &ParseError{Kind: BAD_DIMENSION_ERROR, Line: line, Column: column, Token: token, Err: numberError(err)}
+	case dim < 0:
+		return 0, &ParseError{Kind: NEGATIVE_DIMENSION_ERROR, Line: line, Column: column, Token: token}
+	case dim > MAX_DIMENSION:
+		return 0, &ParseError{Kind: HUGE_DIMENSION_ERROR, Line: line, Column: column, Token: token,
+			Err: fmt.Errorf("maximum is %v", MAX_DIMENSION)}
+	}
+	return dim, nil
 }
 
 func (reader *FileMatrixReader) ReadRow(size int) (*matrix.Row, error) {
@@ -68,11 +184,14 @@ func (reader *FileMatrixReader) ReadMatrix(size int) (*matrix.SquareMatrix, erro
 }
 
 func (reader *FileMatrixReader) Close() error {
+	if reader.file == nil {
+		return nil
+	}
 	return reader.file.Close()
 }
 
 type MatrixReader interface {
-	io.Closer
+	goio.Closer
 	ReadDimension() (int, error)
 	ReadMatrix(size int) (*matrix.SquareMatrix, error)
 	ReadRow(size int) (*matrix.Row, error)
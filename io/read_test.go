@@ -0,0 +1,89 @@
+package io
+
+import (
+	"errors"
+	goio "io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFileMatrixReader(t *testing.T) {
+	content := "# two matrices\n2\n1 2 # first row\n3 4\n\n1#comment right after the dimension\n-0.5e1\n"
+	reader := NewStreamMatrixReader(strings.NewReader(content))
+	defer reader.Close()
+
+	expected := [][][]float64{{{1, 2}, {3, 4}}, {{-5}}}
+	for _, data := range expected {
+		size, err := reader.ReadDimension()
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := reader.ReadMatrix(size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(actual.Data, data) {
+			t.Errorf("got %v, expected %v", actual.Data, data)
+		}
+	}
+
+	_, err := reader.ReadDimension()
+	var parseError *ParseError
+	if !errors.As(err, &parseError) || parseError.Kind != EOF_ERROR || !errors.Is(err, goio.EOF) {
+		t.Errorf("got error %v at the end of input", err)
+	}
+}
+
+func TestFileMatrixReaderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		kind    ParseErrorKind
+		line    int
+		column  int
+		token   string
+	}{
+		{"empty", "", EOF_ERROR, 1, 1, ""},
+		{"comment only", "# nothing\n", EOF_ERROR, 2, 1, ""},
+		{"bad dimension", "  x2\n", BAD_DIMENSION_ERROR, 1, 3, "x2"},
+		{"fractional dimension", "2.5", BAD_DIMENSION_ERROR, 1, 1, "2.5"},
+		{"negative dimension", "\n-3", NEGATIVE_DIMENSION_ERROR, 2, 1, "-3"},
+		{"huge dimension", "100000", HUGE_DIMENSION_ERROR, 1, 1, "100000"},
+		{"dimension out of range", "99999999999999999999", HUGE_DIMENSION_ERROR, 1, 1, "99999999999999999999"},
+		{"bad number", "2\n1 2\n3 y4\n", BAD_NUMBER_ERROR, 3, 3, "y4"},
+		{"truncated", "2\n1 2\n3", TRUNCATED_ERROR, 3, 2, ""},
+		{"truncated after comment", "2\n1 2 3 # the last one is missing", TRUNCATED_ERROR, 2, 32, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := NewStreamMatrixReader(strings.NewReader(test.content))
+			size, err := reader.ReadDimension()
+			if err == nil {
+				_, err = reader.ReadMatrix(size)
+			}
+
+			var parseError *ParseError
+			if !errors.As(err, &parseError) {
+				t.Fatalf("got error %v, expected ParseError", err)
+			}
+			if parseError.Kind != test.kind || parseError.Line != test.line ||
+				parseError.Column != test.column || parseError.Token != test.token {
+				t.Errorf("got %v (token %q), expected %v at line %v, column %v", err, parseError.Token,
+					test.kind, test.line, test.column)
+			}
+		})
+	}
+}
+
+func TestFileMatrixReaderTruncation(t *testing.T) {
+	reader := NewStreamMatrixReader(strings.NewReader("3\n1 2"))
+	if _, err := reader.ReadDimension(); err != nil {
+		t.Fatal(err)
+	}
+	_, err := reader.ReadRow(3)
+	if !errors.Is(err, goio.ErrUnexpectedEOF) || errors.Is(err, goio.EOF) {
+		t.Errorf("got error %v, expected unexpected EOF", err)
+	}
+}
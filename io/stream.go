@@ -0,0 +1,106 @@
+package io
+
+import (
+	"cma-lab-go/matrix"
+	"context"
+	goio "io"
+)
+
+// objects are stored back-to-back: "<n> <n * n numbers>" is a matrix (the old format),
+// tagged objects are "matrix <n> <n * n numbers>", "row <n> <n numbers>", "column <n> <n numbers>"
+const (
+	MATRIX_TAG = "matrix"
+	ROW_TAG    = "row"
+	COLUMN_TAG = "column"
+)
+
+// exactly one of Matrix, Row, Column and Err is set
+type StreamItem struct {
+	Matrix *matrix.SquareMatrix
+	Row    *matrix.Row
+	Column *matrix.Column
+	Err    error
+}
+
+// reads the next object, returns ParseError of EOF_ERROR kind at the end of input
+func (reader *FileMatrixReader) ReadItem() (*StreamItem, error) {
+	token, line, column, err := reader.nextToken()
+	if err == goio.EOF {
+		return nil, &ParseError{Kind: EOF_ERROR, Line: line, Column: column, Err: goio.EOF}
+	}
+	if err != nil {
+		return nil, reader.inputError(err, line, column)
+	}
+
+	tag := MATRIX_TAG
+	switch token {
+	case MATRIX_TAG, ROW_TAG, COLUMN_TAG:
+		tag = token
+		if token, line, column, err = reader.nextToken(); err != nil {
+			return nil, reader.inputError(err, line, column)
+		}
+	}
+
+	dim, err := parseDimension(token, line, column)
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag {
+	case ROW_TAG:
+		row, err := reader.ReadRow(dim)
+		return &StreamItem{Row: row}, err
+	case COLUMN_TAG:
+		col, err := reader.ReadColumn(dim)
+		return &StreamItem{Column: col}, err
+	}
+	m, err := reader.ReadMatrix(dim)
+	return &StreamItem{Matrix: m}, err
+}
+
+// sends objects until the end of input, the error is sent as the last item
+// the channel is closed afterwards or when ctx is cancelled
+func (reader *FileMatrixReader) Stream(ctx context.Context) <-chan *StreamItem {
+	items := make(chan *StreamItem)
+	go func() {
+		defer close(items)
+		for {
+			item, err := reader.ReadItem()
+			if err != nil {
+				if parseError, ok := err.(*ParseError); ok && parseError.Kind == EOF_ERROR {
+					return
+				}
+				item = &StreamItem{Err: err}
+			}
+
+			select {
+			case items <- item:
+			case <-ctx.Done():
+				return
+			}
+			if item.Err != nil {
+				return
+			}
+		}
+	}()
+	return items
+}
+
+// only matrices of the stream, vectors are skipped
+func (reader *FileMatrixReader) Matrices(ctx context.Context) <-chan *StreamItem {
+	matrices := make(chan *StreamItem)
+	go func() {
+		defer close(matrices)
+		for item := range reader.Stream(ctx) {
+			if item.Matrix == nil && item.Err == nil {
+				continue
+			}
+			select {
+			case matrices <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return matrices
+}
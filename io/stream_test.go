@@ -0,0 +1,85 @@
+package io
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	content := "1 5\nrow 2 1 2\nmatrix 2 1 0 0 1\ncolumn 1 7\n"
+	reader := NewStreamMatrixReader(strings.NewReader(content))
+
+	var items []*StreamItem
+	for item := range reader.Stream(context.Background()) {
+		items = append(items, item)
+	}
+	if len(items) != 4 {
+		t.Fatalf("got %v items, expected 4", len(items))
+	}
+	if items[0].Matrix == nil || !reflect.DeepEqual(items[0].Matrix.Data, [][]float64{{5}}) {
+		t.Errorf("item 0 is %+v", items[0])
+	}
+	if items[1].Row == nil || !reflect.DeepEqual(items[1].Row.Data, []float64{1, 2}) {
+		t.Errorf("item 1 is %+v", items[1])
+	}
+	if items[2].Matrix == nil || !reflect.DeepEqual(items[2].Matrix.Data, [][]float64{{1, 0}, {0, 1}}) {
+		t.Errorf("item 2 is %+v", items[2])
+	}
+	if items[3].Column == nil || !reflect.DeepEqual(items[3].Column.Data, []float64{7}) {
+		t.Errorf("item 3 is %+v", items[3])
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		// matrices before the error
+		count int
+		kind  ParseErrorKind
+	}{
+		{"truncated matrix", "1 5\n2 1 2 3", 1, TRUNCATED_ERROR},
+		{"tag without dimension", "row", 0, TRUNCATED_ERROR},
+		{"bad tag", "vector 2 1 2", 0, BAD_DIMENSION_ERROR},
+		{"bad number", "row 2 1 x matrix 1 1", 0, BAD_NUMBER_ERROR},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := NewStreamMatrixReader(strings.NewReader(test.content))
+			count := 0
+			var err error
+			for item := range reader.Matrices(context.Background()) {
+				if item.Err != nil {
+					err = item.Err
+					continue
+				}
+				count++
+			}
+
+			var parseError *ParseError
+			if count != test.count || !errors.As(err, &parseError) || parseError.Kind != test.kind {
+				t.Errorf("got %v matrices and error %v, expected %v matrices and %v", count, err, test.count, test.kind)
+			}
+		})
+	}
+}
+
+func TestStreamCancel(t *testing.T) {
+	reader := NewStreamMatrixReader(strings.NewReader(strings.Repeat("1 1\n", 100)))
+	ctx, cancel := context.WithCancel(context.Background())
+	items := reader.Stream(ctx)
+	<-items
+	cancel()
+	// the channel is closed after the cancellation, the items being sent may still arrive
+	count := 0
+	for range items {
+		count++
+	}
+	if count >= 99 {
+		t.Errorf("got all %v items after the cancellation", count)
+	}
+}
@@ -0,0 +1,242 @@
+package io
+
+import (
+	"cma-lab-go/matrix"
+	"fmt"
+	"math"
+)
+
+// unnamed matrices and vectors are saved as var_0, var_1, ...
+const VARIABLE_NAME = "var_%v"
+
+// named real or complex matrix of Octave and MATLAB files
+// elements are stored by rows, Imag is nil for real variables
+type Variable struct {
+	Name       string
+	Rows, Cols int
+	Data       []float64
+	Imag       []float64
+}
+
+func NewMatrixVariable(name string, squareMatrix *matrix.SquareMatrix) *Variable {
+	size := len(squareMatrix.Data)
+	variable := &Variable{Name: name, Rows: size, Cols: size, Data: make([]float64, 0, size*size)}
+	for _, row := range squareMatrix.Data {
+		variable.Data = append(variable.Data, row...)
+	}
+	return variable
+}
+
+// column vector, as Octave stores them
+func NewVectorVariable(name string, vector []float64) *Variable {
+	data := make([]float64, len(vector))
+	copy(data, vector)
+	return &Variable{Name: name, Rows: len(vector), Cols: 1, Data: data}
+}
+
+func NewComplexVectorVariable(name string, vector []complex128) *Variable {
+	variable := &Variable{
+		Name: name,
+		Rows: len(vector),
+		Cols: 1,
+		Data: make([]float64, 0, len(vector)),
+		Imag: make([]float64, 0, len(vector)),
+	}
+	for _, v := range vector {
+		variable.Data = append(variable.Data, real(v))
+		variable.Imag = append(variable.Imag, imag(v))
+	}
+	return variable
+}
+
+// eigenvalues as a complex column and eigenvectors as columns of the matrix,
+// like [V, D] = eig(A) returns them, missing vectors (complex eigenvalues in SolveQR) are NaN
+func NewEigenVariables(valuesName, vectorsName string, eigenvalues []complex128, eigenvectors [][]float64) []*Variable {
+	// eigenvectors have as many components as there are eigenvalues
+	size := len(eigenvalues)
+	for _, vector := range eigenvectors {
+		if len(vector) > size {
+			size = len(vector)
+		}
+	}
+
+	vectors := &Variable{Name: vectorsName, Rows: size, Cols: len(eigenvalues), Data: make([]float64, size*len(eigenvalues))}
+	for j := range eigenvalues {
+		for i := 0; i < size; i++ {
+			value := math.NaN()
+			if j < len(eigenvectors) && i < len(eigenvectors[j]) {
+				value = eigenvectors[j][i]
+			}
+			vectors.Data[i*vectors.Cols+j] = value
+		}
+	}
+
+	return []*Variable{NewComplexVectorVariable(valuesName, eigenvalues), vectors}
+}
+
+func (variable *Variable) IsComplex() bool {
+	return variable.Imag != nil
+}
+
+func (variable *Variable) checkReal() error {
+	for i, v := range variable.Imag {
+		if v != 0 {
+			return fmt.Errorf("variable %v is complex, element %v has imaginary part %v", variable.Name, i, v)
+		}
+	}
+	return nil
+}
+
+func (variable *Variable) SquareMatrix() (*matrix.SquareMatrix, error) {
+	if variable.Rows != variable.Cols {
+		return &matrix.SquareMatrix{}, fmt.Errorf("variable %v isn't square, got %v x %v",
+			variable.Name, variable.Rows, variable.Cols)
+	}
+	if err := variable.checkReal(); err != nil {
+		return &matrix.SquareMatrix{}, err
+	}
+
+	size := variable.Rows
+	data := make([][]float64, size)
+	for i := range data {
+		data[i] = make([]float64, size)
+		copy(data[i], variable.Data[i*size:(i+1)*size])
+	}
+	return matrix.NewSquareMatrix(data)
+}
+
+func (variable *Variable) isVector() bool {
+	return variable.Rows == 1 || variable.Cols == 1
+}
+
+func (variable *Variable) Vector() ([]float64, error) {
+	if !variable.isVector() {
+		return nil, fmt.Errorf("variable %v isn't a vector, got %v x %v", variable.Name, variable.Rows, variable.Cols)
+	}
+	if err := variable.checkReal(); err != nil {
+		return nil, err
+	}
+
+	result := make([]float64, len(variable.Data))
+	copy(result, variable.Data)
+	return result, nil
+}
+
+func (variable *Variable) ComplexVector() ([]complex128, error) {
+	if !variable.isVector() {
+		return nil, fmt.Errorf("variable %v isn't a vector, got %v x %v", variable.Name, variable.Rows, variable.Cols)
+	}
+
+	result := make([]complex128, 0, len(variable.Data))
+	for i, v := range variable.Data {
+		if variable.IsComplex() {
+			result = append(result, complex(v, variable.Imag[i]))
+		} else {
+			result = append(result, complex(v, 0))
+		}
+	}
+	return result, nil
+}
+
+// MatrixReader over the variables of the file in their order,
+// ReadDimension reads the next variable, the following Read* call returns it
+type variableMatrixReader struct {
+	readVariable func() (*Variable, error)
+
+	pending *Variable
+}
+
+func (reader *variableMatrixReader) next() (*Variable, error) {
+	if reader.pending != nil {
+		variable := reader.pending
+		reader.pending = nil
+		return variable, nil
+	}
+	return reader.readVariable()
+}
+
+func (reader *variableMatrixReader) ReadDimension() (int, error) {
+	variable, err := reader.next()
+	if err != nil {
+		return 0, err
+	}
+	reader.pending = variable
+
+	switch {
+	case variable.Rows == variable.Cols:
+		return variable.Rows, nil
+	case variable.isVector():
+		return variable.Rows * variable.Cols, nil
+	}
+	return 0, fmt.Errorf("variable %v isn't square, got %v x %v", variable.Name, variable.Rows, variable.Cols)
+}
+
+func (reader *variableMatrixReader) ReadMatrix(size int) (*matrix.SquareMatrix, error) {
+	variable, err := reader.next()
+	if err != nil {
+		return &matrix.SquareMatrix{}, err
+	}
+	if variable.Rows != size {
+		return &matrix.SquareMatrix{}, fmt.Errorf("expected %v x %v matrix, got %v x %v",
+			size, size, variable.Rows, variable.Cols)
+	}
+	return variable.SquareMatrix()
+}
+
+func (reader *variableMatrixReader) readVector(size int) ([]float64, error) {
+	variable, err := reader.next()
+	if err != nil {
+		return nil, err
+	}
+	result, err := variable.Vector()
+	if err != nil {
+		return nil, err
+	}
+	if len(result) != size {
+		return nil, fmt.Errorf("expected vector of size %v, got %v x %v", size, variable.Rows, variable.Cols)
+	}
+	return result, nil
+}
+
+func (reader *variableMatrixReader) ReadRow(size int) (*matrix.Row, error) {
+	row, err := reader.readVector(size)
+	return matrix.NewRow(row), err
+}
+
+func (reader *variableMatrixReader) ReadColumn(size int) (*matrix.Column, error) {
+	col, err := reader.readVector(size)
+	return matrix.NewColumn(col), err
+}
+
+// MatrixWriter methods over writeVariable, unnamed objects get VARIABLE_NAME
+type variableMatrixWriter struct {
+	writeVariable func(*Variable)
+
+	count int
+}
+
+func (writer *variableMatrixWriter) writeUnnamed(variable *Variable) {
+	variable.Name = fmt.Sprintf(VARIABLE_NAME, writer.count)
+	writer.count++
+	writer.writeVariable(variable)
+}
+
+func (writer *variableMatrixWriter) WriteMatrix(squareMatrix *matrix.SquareMatrix) {
+	writer.writeUnnamed(NewMatrixVariable("", squareMatrix))
+}
+
+func (writer *variableMatrixWriter) WriteRow(row *matrix.Row) {
+	variable := NewVectorVariable("", row.Data)
+	variable.Rows, variable.Cols = 1, len(row.Data)
+	writer.writeUnnamed(variable)
+}
+
+func (writer *variableMatrixWriter) WriteColumn(column *matrix.Column) {
+	writer.writeUnnamed(NewVectorVariable("", column.Data))
+}
+
+func (writer *variableMatrixWriter) WriteEigenTable(eigenvalues []complex128, eigenvectors [][]float64) {
+	for _, variable := range NewEigenVariables("eigenvalues", "eigenvectors", eigenvalues, eigenvectors) {
+		writer.writeVariable(variable)
+	}
+}
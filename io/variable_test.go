@@ -0,0 +1,150 @@
+package io
+
+import (
+	"cma-lab-go/matrix"
+	goio "io"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+type variableWriter interface {
+	MatrixWriter
+	EigenTableWriter
+	WriteVariable(variable *Variable)
+	Close() error
+}
+
+type variableReader interface {
+	MatrixReader
+	ReadVariable() (*Variable, error)
+}
+
+// NaN values (missing eigenvectors) are equal too
+func sameValues(a, b []float64) bool {
+	if len(a) != len(b) || (a == nil) != (b == nil) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] && !(math.IsNaN(a[i]) && math.IsNaN(b[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+func checkVariable(t *testing.T, actual, expected *Variable) {
+	t.Helper()
+	if actual.Name != expected.Name || actual.Rows != expected.Rows || actual.Cols != expected.Cols ||
+		!sameValues(actual.Data, expected.Data) || !sameValues(actual.Imag, expected.Imag) {
+		t.Errorf("got %+v, expected %+v", actual, expected)
+	}
+}
+
+func TestVariableRoundTrip(t *testing.T) {
+	formats := []struct {
+		name       string
+		openWriter func(filename string) (variableWriter, error)
+		openReader func(filename string) (variableReader, error)
+	}{
+		{"octave",
+			func(filename string) (variableWriter, error) { return NewOctaveMatrixWriter(filename) },
+			func(filename string) (variableReader, error) { return NewOctaveMatrixReader(filename) }},
+		{"mat",
+			func(filename string) (variableWriter, error) { return NewMatMatrixWriter(filename) },
+			func(filename string) (variableReader, error) { return NewMatMatrixReader(filename) }},
+	}
+
+	data := [][]float64{{1, -2.5}, {1e-300, 1.0 / 3}}
+	named := &Variable{Name: "A", Rows: 2, Cols: 3, Data: []float64{1, 2, 3, 4, 5, 6}}
+	eigenvalues := []complex128{2, complex(1, -1), complex(1, 1)}
+	eigenvectors := [][]float64{{1, 0, 0}, nil, nil}
+
+	expected := []*Variable{
+		{Name: "var_0", Rows: 2, Cols: 2, Data: []float64{1, -2.5, 1e-300, 1.0 / 3}},
+		{Name: "var_1", Rows: 1, Cols: 3, Data: []float64{1, 2, 3}},
+		{Name: "var_2", Rows: 3, Cols: 1, Data: []float64{1, 2, 3}},
+		named,
+	}
+	expected = append(expected, NewEigenVariables("eigenvalues", "eigenvectors", eigenvalues, eigenvectors)...)
+
+	for _, format := range formats {
+		t.Run(format.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "variables")
+			writer, err := format.openWriter(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			squareMatrix, _ := matrix.NewSquareMatrix(data)
+			writer.WriteMatrix(squareMatrix)
+			writer.WriteRow(matrix.NewRow([]float64{1, 2, 3}))
+			writer.WriteColumn(matrix.NewColumn([]float64{1, 2, 3}))
+			writer.WriteVariable(named)
+			writer.WriteEigenTable(eigenvalues, eigenvectors)
+			if err := writer.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			reader, err := format.openReader(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+
+			// the first variables are read as matrices and vectors
+			size, err := reader.ReadDimension()
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := reader.ReadMatrix(size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !sameValues(actual.Data[0], data[0]) || !sameValues(actual.Data[1], data[1]) {
+				t.Errorf("got %v, expected %v", actual.Data, data)
+			}
+			row, err := reader.ReadRow(3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !sameValues(row.Data, []float64{1, 2, 3}) {
+				t.Errorf("got %v", row.Data)
+			}
+
+			for _, variable := range expected[2:] {
+				actual, err := reader.ReadVariable()
+				if err != nil {
+					t.Fatal(err)
+				}
+				checkVariable(t, actual, variable)
+			}
+			if _, err := reader.ReadVariable(); err != goio.EOF {
+				t.Errorf("got error %v after the last variable", err)
+			}
+		})
+	}
+}
+
+func TestVariableConversions(t *testing.T) {
+	complexVector := NewComplexVectorVariable("z", []complex128{complex(1, 2), 3})
+	if _, err := complexVector.Vector(); err == nil {
+		t.Errorf("complex variable is converted to a real vector")
+	}
+	if values, err := complexVector.ComplexVector(); err != nil || values[0] != complex(1, 2) || values[1] != 3 {
+		t.Errorf("got %v, %v", values, err)
+	}
+
+	rectangular := &Variable{Name: "r", Rows: 2, Cols: 3, Data: make([]float64, 6)}
+	if _, err := rectangular.SquareMatrix(); err == nil {
+		t.Errorf("rectangular variable is converted to a square matrix")
+	}
+	if _, err := rectangular.Vector(); err == nil {
+		t.Errorf("rectangular variable is converted to a vector")
+	}
+
+	// imaginary parts, which are all zero, don't matter
+	zeroImag := &Variable{Name: "x", Rows: 1, Cols: 1, Data: []float64{1}, Imag: []float64{0}}
+	if squareMatrix, err := zeroImag.SquareMatrix(); err != nil || squareMatrix.Data[0][0] != 1 {
+		t.Errorf("got %v, %v", squareMatrix, err)
+	}
+}
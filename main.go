@@ -1,128 +1,113 @@
 package main
 
 import (
-	"cma-lab-go/cma_methods"
-	"cma-lab-go/io"
-	"cma-lab-go/matrix"
-	"cma-lab-go/utils"
+	"errors"
+	"flag"
 	"fmt"
-	"math/rand"
-	"runtime"
-	"time"
+	goio "io"
+	"os"
 )
 
-func main() {
-	fmt.Println("Cma lab on go! Here we go!")
-	runtime.GOMAXPROCS(20) // number of CPUs [configure it later]
-	rand.Seed(time.Now().UnixNano())
-
-	// Read matrices
-	matrices := []*matrix.SquareMatrix{}
-	for _, filename := range []string{"data/sampleA.txt", "data/sampleB.txt", "data/matrixA.txt", "data/matrixB.txt", } {
-		matrixReader, _ := io.NewFileMatrixReader(filename)
-		dim, _ := matrixReader.ReadDimension()
-		m, _ := matrixReader.ReadMatrix(dim)
-		matrices = append(matrices, m)
-	}
+const PROGRAM_NAME = "cma-lab-go"
 
-	var matrixWriter io.MatrixWriter = io.NewConsoleMatrixWriter()
-
-	// 1. Power method
-	fmt.Println("1. Power method")
-	for _, m := range matrices {
-		matrixWriter.WriteMatrix(m)
-
-		initApprox := make([]float64, len(m.Data))
-		initApprox[0] = 1
-
-		eigenvectors, methodCase, iterations := cma_methods.FindMaxEigenvalues(m, matrix.NewColumn(initApprox))
-		fmt.Printf("Iterations count = %v\n\n", iterations)
-
-		switch methodCase {
-		case cma_methods.REAL_EIGENVALUE_CASE:
-			fmt.Println("REAL_EIGENVALUE_CASE")
-		case cma_methods.OPPOSITE_PAIRED_EIGENVALUES_CASE:
-			fmt.Println("OPPOSITE_PAIRED_EIGENVALUES_CASE")
-		case cma_methods.COMPLEX_EIGENVALUES_CASE:
-			fmt.Println("COMPLEX_EIGENVALUES_CASE")
-		case cma_methods.STUCK_CASE:
-			fmt.Println("Method got stuck")
-			continue
-		}
+const (
+	EXIT_SUCCESS = 0
+	// solver or input/output errors
+	EXIT_FAILURE = 1
+	// unknown command, bad flags or arguments
+	EXIT_USAGE = 2
+	// verify found eigenpairs with residuals above the tolerance
+	EXIT_VERIFY_FAILED = 3
+)
 
-		for _, v := range eigenvectors {
-			fmt.Printf("Eigenvalue = %v\n", v.Value)
-			fmt.Printf("Eigenvector = %v\n", v.Vector)
-		}
-	}
+type command struct {
+	name        string
+	description string
+	run         func(args []string) error
+}
 
-	// 2. Danilevskii method
-	fmt.Println("\n2. Danilevskii method")
-	for _, m := range matrices {
-		matrixWriter.WriteMatrix(m)
+var commands = []*command{
+	{name: "eigen", description: "eigenvalues and eigenvectors of the input matrices", run: runEigen},
+	{name: "charpoly", description: "characteristic polynomials of the input matrices", run: runCharpoly},
+	{name: "roots", description: "eigenvalues as the roots of the characteristic polynomials", run: runRoots},
+	{name: "bench", description: "time of the eigenvalue methods on random matrices", run: runBench},
+	{name: "generate", description: "random matrices in any of the supported formats", run: runGenerate},
+	{name: "verify", description: "check the eigenpairs of the input matrices by their residuals", run: runVerify},
+}
 
-		polynomial, transform, blocksSplit := cma_methods.FindPolynomial(m)
+// bad flags or arguments, the message is printed unless the flag package has already done it
+type usageError struct {
+	err      error
+	reported bool
+}
 
-		first := true
-		for i, v := range polynomial {
-			if !first {
-				fmt.Print(" + ")
-			}
-			fmt.Printf("%vx^{%v}", v, i)
-			first = false
-		}
-		fmt.Printf("\n\n")
-
-		eigenvalues := cma_methods.FindPolynomialRoots(polynomial)
-		fmt.Printf("Eigenvalues = %v\n", eigenvalues)
-
-		if blocksSplit {
-			fmt.Printf("Unable to recover eigenvectors, because there was a block split\n\n")
-		} else {
-			for _, eigenvalue := range eigenvalues {
-				fmt.Println("")
-				eigenvector := make([]float64, len(transform.Data))
-				var lambda float64 = 1
-				for i := len(transform.Data) - 1; i >= 0; i-- {
-					eigenvector[i] = lambda
-					lambda *= eigenvalue
-				}
-
-				eigenvectorTransformed, _ := matrix.MultiplyMatrixOnColumn(transform, matrix.NewColumn(eigenvector))
-
-				fmt.Printf("Eigenvalue = %v\n", eigenvalue)
-				fmt.Printf("Eigenvector = %v\n", eigenvectorTransformed.Data)
-			}
+func (err *usageError) Error() string {
+	return err.err.Error()
+}
+
+func (err *usageError) Unwrap() error {
+	return err.err
+}
+
+func usageErrorf(format string, args ...interface{}) error {
+	return &usageError{err: fmt.Errorf(format, args...)}
+}
+
+var errVerifyFailed = errors.New("verification failed")
+
+func exitCode(err error) int {
+	var usage *usageError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return EXIT_SUCCESS
+	case errors.As(err, &usage):
+		return EXIT_USAGE
+	case errors.Is(err, errVerifyFailed):
+		return EXIT_VERIFY_FAILED
+	}
+	return EXIT_FAILURE
+}
+
+func printUsage(writer goio.Writer) {
+	fmt.Fprintf(writer, "Usage: %v <command> [flags] [input ...]\n\nCommands:\n", PROGRAM_NAME)
+	for _, c := range commands {
+		fmt.Fprintf(writer, "  %-10v %v\n", c.name, c.description)
+	}
+	fmt.Fprintf(writer, "\nRun \"%v <command> -h\" for the flags of the command.\n", PROGRAM_NAME)
+}
+
+func findCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
 		}
 	}
+	return nil
+}
 
-	// 3. QR-algorithm
-	fmt.Println("3. QR-algorithm")
-	for _, m := range matrices {
-		matrixWriter.WriteMatrix(m)
+func main() {
+	if len(os.Args) < 2 {
+		printUsage(os.Stderr)
+		os.Exit(EXIT_USAGE)
+	}
 
-		eigenvalues, eigenvectors, iterations := cma_methods.SolveQR(m)
-		fmt.Printf("Iterations count = %v\n\n", iterations)
-		fmt.Println(eigenvalues)
+	switch os.Args[1] {
+	case "help", "-h", "-help", "--help":
+		printUsage(os.Stdout)
+		os.Exit(EXIT_SUCCESS)
+	}
 
-		for i, _ := range eigenvectors {
-			fmt.Printf("Eigenvalue = %v\n", eigenvalues[i])
-			fmt.Printf("Eigenvector = %v\n\n", eigenvectors[i])
-		}
-		fmt.Println()
+	c := findCommand(os.Args[1])
+	if c == nil {
+		fmt.Fprintf(os.Stderr, "%v: unknown command %q\n\n", PROGRAM_NAME, os.Args[1])
+		printUsage(os.Stderr)
+		os.Exit(EXIT_USAGE)
 	}
 
-	// measure QR-algorithm time
-	min, max := -1000000000.0, 1000000000.0
-	for _, size := range []int{
-		10, 20, 30, 40, 50, 60, 70, 80, 90, 100, 110,
-		120, 130, 140, 150, 160, 170, 180, 190, 200,
-	} {
-		m := utils.GenerateSquareMatrix(size, min, max)
-
-		start := time.Now()
-		_, _, _ = cma_methods.SolveQR(m)
-		fmt.Printf("Size = %v => time = %v milliseconds\n", size,
-			time.Since(start).Milliseconds())
+	err := c.run(os.Args[2:])
+	var usage *usageError
+	if err != nil && !errors.Is(err, flag.ErrHelp) && !(errors.As(err, &usage) && usage.reported) {
+		fmt.Fprintf(os.Stderr, "%v %v: %v\n", PROGRAM_NAME, c.name, err)
 	}
+	os.Exit(exitCode(err))
 }
@@ -0,0 +1,52 @@
+package matrix
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// matrices are marshalled as arrays of rows, vectors as plain arrays
+
+func (squareMatrix SquareMatrix) MarshalJSON() ([]byte, error) {
+	if squareMatrix.Data == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(squareMatrix.Data)
+}
+
+func (squareMatrix *SquareMatrix) UnmarshalJSON(data []byte) error {
+	var rows [][]float64
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return err
+	}
+	for i := range rows {
+		if len(rows[i]) != len(rows) {
+			return fmt.Errorf("row %v: expected %v elements, got %v", i, len(rows), len(rows[i]))
+		}
+	}
+	squareMatrix.Data = rows
+	return nil
+}
+
+func marshalVector(data []float64) ([]byte, error) {
+	if data == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(data)
+}
+
+func (column Column) MarshalJSON() ([]byte, error) {
+	return marshalVector(column.Data)
+}
+
+func (column *Column) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &column.Data)
+}
+
+func (row Row) MarshalJSON() ([]byte, error) {
+	return marshalVector(row.Data)
+}
+
+func (row *Row) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &row.Data)
+}
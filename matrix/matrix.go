@@ -129,3 +129,17 @@ func (squareMatrix *SquareMatrix) Transpose() {
 		}
 	}
 }
+
+// Deep copy of the matrix, so the result can be changed
+// without side effects on the original one
+func (squareMatrix *SquareMatrix) Copy() *SquareMatrix {
+	size := len(squareMatrix.Data)
+
+	result := SquareMatrix{}
+	result.Data = make([][]float64, size)
+	for i := 0; i < size; i++ {
+		result.Data[i] = make([]float64, size)
+		copy(result.Data[i], squareMatrix.Data[i])
+	}
+	return &result
+}
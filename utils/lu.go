@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"cma-lab-go/matrix"
+	"fmt"
+	"math"
+)
+
+// P * A = L * U, L and U are stored in one matrix
+// (L has unit diagonal, which is not stored)
+type LU struct {
+	Data   [][]float64
+	Pivots []int
+}
+
+// LU decomposition with partial pivoting
+func NewLU(squareMatrix *matrix.SquareMatrix) (*LU, error) {
+	size := len(squareMatrix.Data)
+	data := squareMatrix.Copy().Data
+	pivots := make([]int, size)
+
+	for k := 0; k < size; k++ {
+		maxInd := k
+		for i := k + 1; i < size; i++ {
+			if math.Abs(data[i][k]) > math.Abs(data[maxInd][k]) {
+				maxInd = i
+			}
+		}
+		pivots[k] = maxInd
+		data[k], data[maxInd] = data[maxInd], data[k]
+
+		if data[k][k] == 0 {
+			return &LU{}, fmt.Errorf("matrix is singular")
+		}
+
+		for i := k + 1; i < size; i++ {
+			data[i][k] /= data[k][k]
+			for j := k + 1; j < size; j++ {
+				data[i][j] -= data[i][k] * data[k][j]
+			}
+		}
+	}
+
+	return &LU{Data: data, Pivots: pivots}, nil
+}
+
+// solves A * x = b
+func (lu *LU) Solve(b []float64) []float64 {
+	size := len(lu.Data)
+	x := make([]float64, size)
+	copy(x, b)
+
+	for k := 0; k < size; k++ {
+		x[k], x[lu.Pivots[k]] = x[lu.Pivots[k]], x[k]
+	}
+	for i := 0; i < size; i++ {
+		for k := 0; k < i; k++ {
+			x[i] -= lu.Data[i][k] * x[k]
+		}
+	}
+	for i := size - 1; i >= 0; i-- {
+		for k := i + 1; k < size; k++ {
+			x[i] -= lu.Data[i][k] * x[k]
+		}
+		x[i] /= lu.Data[i][i]
+	}
+	return x
+}
+
+// solves A^T * x = b
+func (lu *LU) SolveTransposed(b []float64) []float64 {
+	size := len(lu.Data)
+	x := make([]float64, size)
+	copy(x, b)
+
+	// U^T * z = b
+	for i := 0; i < size; i++ {
+		for k := 0; k < i; k++ {
+			x[i] -= lu.Data[k][i] * x[k]
+		}
+		x[i] /= lu.Data[i][i]
+	}
+	// L^T * w = z
+	for i := size - 1; i >= 0; i-- {
+		for k := i + 1; k < size; k++ {
+			x[i] -= lu.Data[k][i] * x[k]
+		}
+	}
+	// x = P^T * w
+	for k := size - 1; k >= 0; k-- {
+		x[k], x[lu.Pivots[k]] = x[lu.Pivots[k]], x[k]
+	}
+	return x
+}
+
+func (lu *LU) Determinant() float64 {
+	var det float64 = 1
+	for i := range lu.Data {
+		det *= lu.Data[i][i]
+		if lu.Pivots[i] != i {
+			det = -det
+		}
+	}
+	return det
+}
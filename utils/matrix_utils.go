@@ -2,7 +2,6 @@ package utils
 
 import (
 	"cma-lab-go/matrix"
-	"math"
 	"math/rand"
 )
 
@@ -43,11 +42,7 @@ func GenerateVector(size int32, min, max float64) []float64 {
 
 // max norm is used
 func GetNorm(vector []float64) float64 {
-	var max float64 = 0
-	for _, v := range vector {
-		max = math.Max(max, math.Abs(v))
-	}
-	return max
+	return VectorNormInf(vector)
 }
 
 func NormColumn(column *matrix.Column) {
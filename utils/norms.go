@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"cma-lab-go/matrix"
+	"math"
+)
+
+const (
+	SPECTRAL_NORM_ACCURACY       = 1e-12
+	SPECTRAL_NORM_MAX_ITERATIONS = 1000
+	CONDITION_ESTIMATOR_STEPS    = 5
+)
+
+// Vector norms
+
+func VectorNorm1(vector []float64) float64 {
+	var sum float64 = 0
+	for _, v := range vector {
+		sum += math.Abs(v)
+	}
+	return sum
+}
+
+// scaled to avoid overflow
+func VectorNorm2(vector []float64) float64 {
+	max := VectorNormInf(vector)
+	if max == 0 {
+		return 0
+	}
+
+	var sum float64 = 0
+	for _, v := range vector {
+		sum += (v / max) * (v / max)
+	}
+	return max * math.Sqrt(sum)
+}
+
+func VectorNormInf(vector []float64) float64 {
+	var max float64 = 0
+	for _, v := range vector {
+		max = math.Max(max, math.Abs(v))
+	}
+	return max
+}
+
+// Matrix norms
+
+// max column sum
+func MatrixNorm1(squareMatrix *matrix.SquareMatrix) float64 {
+	size := len(squareMatrix.Data)
+
+	var max float64 = 0
+	for j := 0; j < size; j++ {
+		var sum float64 = 0
+		for i := 0; i < size; i++ {
+			sum += math.Abs(squareMatrix.Data[i][j])
+		}
+		max = math.Max(max, sum)
+	}
+	return max
+}
+
+// max row sum
+func MatrixNormInf(squareMatrix *matrix.SquareMatrix) float64 {
+	var max float64 = 0
+	for _, row := range squareMatrix.Data {
+		max = math.Max(max, VectorNorm1(row))
+	}
+	return max
+}
+
+func FrobeniusNorm(squareMatrix *matrix.SquareMatrix) float64 {
+	var sum float64 = 0
+	for _, row := range squareMatrix.Data {
+		for _, v := range row {
+			sum += v * v
+		}
+	}
+	return math.Sqrt(sum)
+}
+
+// largest singular value, power method for A^T * A
+func SpectralNorm(squareMatrix *matrix.SquareMatrix) float64 {
+	size := len(squareMatrix.Data)
+	if size == 0 {
+		return 0
+	}
+
+	transposed := squareMatrix.Copy()
+	transposed.Transpose()
+
+	vector := matrix.NewColumn(make([]float64, size))
+	for i := range vector.Data {
+		vector.Data[i] = 1 / math.Sqrt(float64(size))
+	}
+
+	var sigma float64 = 0
+	for iteration := 0; iteration < SPECTRAL_NORM_MAX_ITERATIONS; iteration++ {
+		image, _ := matrix.MultiplyMatrixOnColumn(squareMatrix, vector)
+		next, _ := matrix.MultiplyMatrixOnColumn(transposed, image)
+
+		norm := VectorNorm2(next.Data)
+		if norm == 0 {
+			return 0
+		}
+		for i := range next.Data {
+			next.Data[i] /= norm
+		}
+		vector = next
+
+		prev := sigma
+		sigma = math.Sqrt(norm)
+		if math.Abs(sigma-prev) <= SPECTRAL_NORM_ACCURACY*sigma {
+			break
+		}
+	}
+	return sigma
+}
+
+// Hager's estimate of ||A^{-1}||_1 with Higham's extra test vector
+func estimateInverseNorm1(lu *LU) float64 {
+	size := len(lu.Data)
+
+	x := make([]float64, size)
+	for i := range x {
+		x[i] = 1 / float64(size)
+	}
+
+	var estimate float64 = 0
+	for step := 0; step < CONDITION_ESTIMATOR_STEPS; step++ {
+		y := lu.Solve(x)
+		estimate = math.Max(estimate, VectorNorm1(y))
+
+		sign := make([]float64, size)
+		for i, v := range y {
+			if v >= 0 {
+				sign[i] = 1
+			} else {
+				sign[i] = -1
+			}
+		}
+
+		z := lu.SolveTransposed(sign)
+		var zx float64 = 0
+		maxInd := 0
+		for i, v := range z {
+			zx += v * x[i]
+			if math.Abs(v) > math.Abs(z[maxInd]) {
+				maxInd = i
+			}
+		}
+
+		if math.Abs(z[maxInd]) <= zx {
+			break
+		}
+
+		x = make([]float64, size)
+		x[maxInd] = 1
+	}
+
+	// x_i = (-1)^i * (1 + i / (n - 1))
+	for i := range x {
+		x[i] = 1
+		if size > 1 {
+			x[i] += float64(i) / float64(size-1)
+		}
+		if i%2 == 1 {
+			x[i] = -x[i]
+		}
+	}
+	alternative := 2 * VectorNorm1(lu.Solve(x)) / float64(3*size)
+
+	return math.Max(estimate, alternative)
+}
+
+// estimate of the 1-norm condition number ||A||_1 * ||A^{-1}||_1
+func EstimateCondition1(squareMatrix *matrix.SquareMatrix) (float64, error) {
+	lu, err := NewLU(squareMatrix)
+	if err != nil {
+		return math.Inf(1), err
+	}
+	return MatrixNorm1(squareMatrix) * estimateInverseNorm1(lu), nil
+}